@@ -0,0 +1,330 @@
+package openrouter
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+)
+
+// ChatStreamAccumulator reassembles streamed chat completion chunks into a complete
+// ChatCompletionResponse. It merges content, reasoning, audio and image deltas, tool
+// call fragments, annotations and log probabilities per choice index, and keeps the
+// usage reported by the final chunk.
+//
+// An accumulator is not safe for concurrent use.
+//
+// Example:
+//
+//	acc := openrouter.NewChatStreamAccumulator()
+//	for chunk := range stream.Events() {
+//	    acc.Add(chunk)
+//	}
+//	if err := stream.Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+//	resp := acc.Response()
+//	for _, call := range resp.Choices[0].Message.ToolCalls {
+//	    fmt.Println(call.Function.Name, call.Function.Arguments)
+//	}
+type ChatStreamAccumulator struct {
+	id                string
+	model             string
+	provider          string
+	created           int64
+	systemFingerprint string
+	usage             Usage
+	choices           map[int]*accumulatedChoice
+}
+
+// accumulatedChoice holds the partial state of a single streamed choice.
+type accumulatedChoice struct {
+	role         string
+	name         string
+	content      strings.Builder
+	reasoning    strings.Builder
+	audio        *accumulatedAudio
+	images       []MessageImage
+	toolCalls    []*ToolCall
+	toolCallPos  map[int]int
+	details      []*ReasoningDetail
+	detailPos    map[int]int
+	annotations  []Annotation
+	logProbs     *LogProbs
+	finishReason string
+}
+
+// accumulatedAudio holds the partial state of streamed audio output.
+type accumulatedAudio struct {
+	id         string
+	expiresAt  int64
+	data       []byte
+	transcript strings.Builder
+}
+
+// NewChatStreamAccumulator creates an empty accumulator.
+func NewChatStreamAccumulator() *ChatStreamAccumulator {
+	return &ChatStreamAccumulator{
+		choices: make(map[int]*accumulatedChoice),
+	}
+}
+
+// Add merges a single stream chunk into the accumulated response. A chunk that
+// restarts the stream after a reconnection discards the state accumulated so far.
+func (a *ChatStreamAccumulator) Add(chunk ChatCompletionResponse) {
+	if chunk.Reconnect != nil && chunk.Reconnect.Strategy == ReconnectRestart {
+		*a = *NewChatStreamAccumulator()
+	}
+
+	if chunk.ID != "" {
+		a.id = chunk.ID
+	}
+	if chunk.Model != "" {
+		a.model = chunk.Model
+	}
+	if chunk.Provider != "" {
+		a.provider = chunk.Provider
+	}
+	if chunk.Created != 0 {
+		a.created = chunk.Created
+	}
+	if chunk.SystemFingerprint != "" {
+		a.systemFingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage != (Usage{}) {
+		a.usage = chunk.Usage
+	}
+
+	for _, choice := range chunk.Choices {
+		a.addChoice(choice)
+	}
+}
+
+// addChoice merges a single streamed choice into its accumulated state.
+func (a *ChatStreamAccumulator) addChoice(choice Choice) {
+	acc, ok := a.choices[choice.Index]
+	if !ok {
+		acc = &accumulatedChoice{toolCallPos: make(map[int]int), detailPos: make(map[int]int)}
+		a.choices[choice.Index] = acc
+	}
+
+	if choice.FinishReason != "" {
+		acc.finishReason = choice.FinishReason
+	}
+
+	if choice.LogProbs != nil {
+		if acc.logProbs == nil {
+			acc.logProbs = &LogProbs{}
+		}
+		acc.logProbs.Content = append(acc.logProbs.Content, choice.LogProbs.Content...)
+	}
+
+	delta := choice.Delta
+	if delta == nil {
+		// Some providers send the full message on the final chunk instead of a delta
+		delta = &choice.Message
+	}
+
+	if delta.Role != "" {
+		acc.role = delta.Role
+	}
+	if delta.Name != "" {
+		acc.name = delta.Name
+	}
+	if content, ok := delta.Content.(string); ok {
+		acc.content.WriteString(content)
+	}
+	acc.reasoning.WriteString(delta.Reasoning)
+	acc.annotations = append(acc.annotations, delta.Annotations...)
+
+	for _, call := range delta.ToolCalls {
+		acc.addToolCall(call)
+	}
+	for _, detail := range delta.ReasoningDetails {
+		acc.addReasoningDetail(detail)
+	}
+	if delta.Audio != nil {
+		acc.addAudio(delta.Audio)
+	}
+	// Generated images arrive complete in a single delta
+	acc.images = append(acc.images, delta.Images...)
+}
+
+// addAudio merges a streamed audio chunk. Each chunk is base64 encoded on its own,
+// so chunks are decoded before they are joined; chunks that fail to decode are dropped.
+func (acc *accumulatedChoice) addAudio(chunk *MessageAudio) {
+	if acc.audio == nil {
+		acc.audio = &accumulatedAudio{}
+	}
+
+	if chunk.ID != "" {
+		acc.audio.id = chunk.ID
+	}
+	if chunk.ExpiresAt != 0 {
+		acc.audio.expiresAt = chunk.ExpiresAt
+	}
+	acc.audio.transcript.WriteString(chunk.Transcript)
+
+	if data, err := chunk.Decode(); err == nil {
+		acc.audio.data = append(acc.audio.data, data...)
+	}
+}
+
+// addReasoningDetail merges a reasoning detail fragment into the accumulated
+// details. Fragments with the same stream index belong to the same block; their
+// text is concatenated. Fragments without an index are separate blocks.
+func (acc *accumulatedChoice) addReasoningDetail(fragment ReasoningDetail) {
+	if fragment.Index == nil {
+		detail := fragment
+		acc.details = append(acc.details, &detail)
+		return
+	}
+
+	pos, ok := acc.detailPos[*fragment.Index]
+	if !ok {
+		detail := fragment
+		acc.detailPos[*fragment.Index] = len(acc.details)
+		acc.details = append(acc.details, &detail)
+		return
+	}
+
+	detail := acc.details[pos]
+	if fragment.Type != "" {
+		detail.Type = fragment.Type
+	}
+	if fragment.ID != "" {
+		detail.ID = fragment.ID
+	}
+	if fragment.Format != "" {
+		detail.Format = fragment.Format
+	}
+	if fragment.Signature != "" {
+		detail.Signature = fragment.Signature
+	}
+	detail.Text += fragment.Text
+	detail.Summary += fragment.Summary
+	detail.Data += fragment.Data
+}
+
+// addToolCall merges a tool call fragment into the accumulated tool calls.
+// Fragments are matched by their stream index; fragments without an index are
+// appended to the most recent call unless they carry a new call ID.
+func (acc *accumulatedChoice) addToolCall(fragment ToolCall) {
+	var call *ToolCall
+
+	if fragment.Index != nil {
+		if pos, ok := acc.toolCallPos[*fragment.Index]; ok {
+			call = acc.toolCalls[pos]
+		} else {
+			call = &ToolCall{}
+			acc.toolCallPos[*fragment.Index] = len(acc.toolCalls)
+			acc.toolCalls = append(acc.toolCalls, call)
+		}
+	} else {
+		last := len(acc.toolCalls) - 1
+		if last >= 0 && (fragment.ID == "" || fragment.ID == acc.toolCalls[last].ID) {
+			call = acc.toolCalls[last]
+		} else {
+			call = &ToolCall{}
+			acc.toolCalls = append(acc.toolCalls, call)
+		}
+	}
+
+	if fragment.ID != "" {
+		call.ID = fragment.ID
+	}
+	if fragment.Type != "" {
+		call.Type = fragment.Type
+	}
+	if fragment.Function.Name != "" {
+		call.Function.Name = fragment.Function.Name
+	}
+	call.Function.Arguments += fragment.Function.Arguments
+}
+
+// Response returns the response assembled from all chunks added so far.
+// It can be called at any point, including before the stream has finished.
+func (a *ChatStreamAccumulator) Response() *ChatCompletionResponse {
+	resp := &ChatCompletionResponse{
+		ID:                a.id,
+		Object:            "chat.completion",
+		Created:           a.created,
+		Model:             a.model,
+		Provider:          a.provider,
+		Usage:             a.usage,
+		SystemFingerprint: a.systemFingerprint,
+		Choices:           make([]Choice, 0, len(a.choices)),
+	}
+
+	indexes := make([]int, 0, len(a.choices))
+	for index := range a.choices {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		acc := a.choices[index]
+
+		role := acc.role
+		if role == "" {
+			role = "assistant"
+		}
+
+		message := Message{
+			Role:        role,
+			Content:     acc.content.String(),
+			Name:        acc.name,
+			Annotations: acc.annotations,
+			Reasoning:   acc.reasoning.String(),
+			Images:      acc.images,
+		}
+
+		for _, detail := range acc.details {
+			message.ReasoningDetails = append(message.ReasoningDetails, *detail)
+		}
+
+		if acc.audio != nil {
+			message.Audio = &MessageAudio{
+				ID:         acc.audio.id,
+				Data:       base64.StdEncoding.EncodeToString(acc.audio.data),
+				Transcript: acc.audio.transcript.String(),
+				ExpiresAt:  acc.audio.expiresAt,
+			}
+		}
+
+		for _, call := range acc.toolCalls {
+			callType := call.Type
+			if callType == "" {
+				callType = "function"
+			}
+			message.ToolCalls = append(message.ToolCalls, ToolCall{
+				ID:       call.ID,
+				Type:     callType,
+				Function: call.Function,
+			})
+		}
+
+		resp.Choices = append(resp.Choices, Choice{
+			Index:        index,
+			Message:      message,
+			FinishReason: acc.finishReason,
+			LogProbs:     acc.logProbs,
+		})
+	}
+
+	return resp
+}
+
+// AccumulateChatStream consumes all events from a chat stream and returns the
+// reassembled response. The stream is drained but not closed.
+func AccumulateChatStream(stream *ChatStream) (*ChatCompletionResponse, error) {
+	acc := NewChatStreamAccumulator()
+	for chunk := range stream.Events() {
+		acc.Add(chunk)
+	}
+
+	if err := stream.Err(); err != nil {
+		return acc.Response(), err
+	}
+
+	return acc.Response(), nil
+}
@@ -0,0 +1,223 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChatStreamAccumulatorContent(t *testing.T) {
+	chunks := []string{
+		`{"id":"chat-1","model":"test-model","created":100,"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+		`{"id":"chat-1","model":"test-model","choices":[{"index":0,"delta":{"content":" world","annotations":[{"type":"url_citation","url_citation":{"url":"https://example.com","title":"Example"}}]}}]}`,
+		`{"id":"chat-1","model":"test-model","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`{"id":"chat-1","model":"test-model","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`,
+	}
+
+	acc := NewChatStreamAccumulator()
+	for _, data := range chunks {
+		var chunk ChatCompletionResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed to unmarshal chunk: %v", err)
+		}
+		acc.Add(chunk)
+	}
+
+	resp := acc.Response()
+
+	if resp.ID != "chat-1" {
+		t.Errorf("expected ID 'chat-1', got %q", resp.ID)
+	}
+	if resp.Created != 100 {
+		t.Errorf("expected created 100, got %d", resp.Created)
+	}
+	if len(resp.Choices) != 1 {
+		t.Fatalf("expected 1 choice, got %d", len(resp.Choices))
+	}
+
+	choice := resp.Choices[0]
+	if choice.Message.Role != "assistant" {
+		t.Errorf("expected role 'assistant', got %q", choice.Message.Role)
+	}
+	if choice.Message.Content != "Hello world" {
+		t.Errorf("expected content 'Hello world', got %q", choice.Message.Content)
+	}
+	if choice.FinishReason != "stop" {
+		t.Errorf("expected finish reason 'stop', got %q", choice.FinishReason)
+	}
+	if len(choice.Message.Annotations) != 1 {
+		t.Errorf("expected 1 annotation, got %d", len(choice.Message.Annotations))
+	}
+	if resp.Usage.TotalTokens != 7 {
+		t.Errorf("expected 7 total tokens, got %d", resp.Usage.TotalTokens)
+	}
+}
+
+func TestChatStreamAccumulatorToolCalls(t *testing.T) {
+	chunks := []string{
+		`{"id":"chat-2","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`{"id":"chat-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"location\":"}}]}}]}`,
+		`{"id":"chat-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}`,
+		`{"id":"chat-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`{"id":"chat-2","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+	}
+
+	acc := NewChatStreamAccumulator()
+	for _, data := range chunks {
+		var chunk ChatCompletionResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed to unmarshal chunk: %v", err)
+		}
+		acc.Add(chunk)
+	}
+
+	resp := acc.Response()
+	calls := resp.Choices[0].Message.ToolCalls
+
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(calls))
+	}
+	if calls[0].ID != "call_a" || calls[0].Function.Name != "get_weather" {
+		t.Errorf("unexpected first tool call: %+v", calls[0])
+	}
+	if calls[0].Function.Arguments != `{"location":"Paris"}` {
+		t.Errorf("unexpected arguments: %q", calls[0].Function.Arguments)
+	}
+	if calls[0].Index != nil {
+		t.Error("expected assembled tool call to have no stream index")
+	}
+	if calls[1].ID != "call_b" || calls[1].Function.Arguments != "{}" {
+		t.Errorf("unexpected second tool call: %+v", calls[1])
+	}
+	if resp.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("expected finish reason 'tool_calls', got %q", resp.Choices[0].FinishReason)
+	}
+}
+
+func TestChatStreamAccumulatorToolCallsWithoutIndex(t *testing.T) {
+	acc := NewChatStreamAccumulator()
+	acc.Add(ChatCompletionResponse{Choices: []Choice{{Delta: &Message{ToolCalls: []ToolCall{
+		{ID: "call_1", Function: FunctionCall{Name: "first", Arguments: `{"a":`}},
+	}}}}})
+	acc.Add(ChatCompletionResponse{Choices: []Choice{{Delta: &Message{ToolCalls: []ToolCall{
+		{Function: FunctionCall{Arguments: `1}`}},
+	}}}}})
+	acc.Add(ChatCompletionResponse{Choices: []Choice{{Delta: &Message{ToolCalls: []ToolCall{
+		{ID: "call_2", Function: FunctionCall{Name: "second", Arguments: `{}`}},
+	}}}}})
+
+	calls := acc.Response().Choices[0].Message.ToolCalls
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(calls))
+	}
+	if calls[0].Function.Arguments != `{"a":1}` {
+		t.Errorf("unexpected arguments: %q", calls[0].Function.Arguments)
+	}
+	if calls[0].Type != "function" {
+		t.Errorf("expected default type 'function', got %q", calls[0].Type)
+	}
+}
+
+func TestChatStreamAccumulatorMultipleChoices(t *testing.T) {
+	acc := NewChatStreamAccumulator()
+	acc.Add(ChatCompletionResponse{Choices: []Choice{
+		{Index: 1, Delta: &Message{Content: "B"}},
+		{Index: 0, Delta: &Message{Content: "A"}},
+	}})
+	acc.Add(ChatCompletionResponse{Choices: []Choice{
+		{Index: 0, Delta: &Message{Content: "a"}, FinishReason: "stop"},
+		{Index: 1, Delta: &Message{Content: "b"}, FinishReason: "length"},
+	}})
+
+	resp := acc.Response()
+	if len(resp.Choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(resp.Choices))
+	}
+	if resp.Choices[0].Index != 0 || resp.Choices[0].Message.Content != "Aa" {
+		t.Errorf("unexpected first choice: %+v", resp.Choices[0])
+	}
+	if resp.Choices[1].Index != 1 || resp.Choices[1].Message.Content != "Bb" || resp.Choices[1].FinishReason != "length" {
+		t.Errorf("unexpected second choice: %+v", resp.Choices[1])
+	}
+}
+
+func TestAccumulateChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+
+		events := []string{
+			`data: {"id":"chat-3","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":"}}]}}]}`,
+			`data: {"id":"chat-3","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"go\"}"}}]},"finish_reason":"tool_calls"}]}`,
+			`data: [DONE]`,
+		}
+		for _, event := range events {
+			w.Write([]byte(event + "\n\n"))
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	stream, err := client.ChatCompleteStream(context.Background(),
+		[]Message{CreateUserMessage("Hello")},
+		WithModel("test-model"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	resp, err := AccumulateChatStream(stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := resp.Choices[0].Message.ToolCalls
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(calls))
+	}
+	if calls[0].Function.Arguments != `{"q":"go"}` {
+		t.Errorf("unexpected arguments: %q", calls[0].Function.Arguments)
+	}
+}
+
+func TestChatStreamAccumulatorReasoning(t *testing.T) {
+	chunks := []string{
+		`{"id":"chat-r","choices":[{"index":0,"delta":{"role":"assistant","reasoning":"Let me ","reasoning_details":[{"type":"reasoning.text","index":0,"format":"anthropic-claude-v1","text":"Let me "}]}}]}`,
+		`{"id":"chat-r","choices":[{"index":0,"delta":{"reasoning":"think.","reasoning_details":[{"type":"reasoning.text","index":0,"text":"think.","signature":"sig-1"}]}}]}`,
+		`{"id":"chat-r","choices":[{"index":0,"delta":{"reasoning_details":[{"type":"reasoning.encrypted","index":1,"data":"opaque"}]}}]}`,
+		`{"id":"chat-r","choices":[{"index":0,"delta":{"content":"42"},"finish_reason":"stop"}]}`,
+	}
+
+	acc := NewChatStreamAccumulator()
+	for _, data := range chunks {
+		var chunk ChatCompletionResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed to unmarshal chunk: %v", err)
+		}
+		acc.Add(chunk)
+	}
+
+	message := acc.Response().Choices[0].Message
+	if message.Content != "42" {
+		t.Errorf("expected content '42', got %q", message.Content)
+	}
+	if message.Reasoning != "Let me think." {
+		t.Errorf("expected reasoning 'Let me think.', got %q", message.Reasoning)
+	}
+	if len(message.ReasoningDetails) != 2 {
+		t.Fatalf("expected 2 reasoning details, got %d", len(message.ReasoningDetails))
+	}
+
+	text := message.ReasoningDetails[0]
+	if text.Type != ReasoningDetailText || text.Text != "Let me think." || text.Signature != "sig-1" || text.Format != "anthropic-claude-v1" {
+		t.Errorf("unexpected text detail: %+v", text)
+	}
+	if encrypted := message.ReasoningDetails[1]; encrypted.Type != ReasoningDetailEncrypted || encrypted.Data != "opaque" {
+		t.Errorf("unexpected encrypted detail: %+v", encrypted)
+	}
+}
@@ -0,0 +1,105 @@
+package openrouter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AudioFormat represents the encoding of audio input or output.
+type AudioFormat string
+
+const (
+	// AudioFormatWAV is WAV audio, supported for input and output
+	AudioFormatWAV AudioFormat = "wav"
+	// AudioFormatMP3 is MP3 audio, supported for input and output
+	AudioFormatMP3 AudioFormat = "mp3"
+	// AudioFormatFLAC is FLAC audio, supported for output
+	AudioFormatFLAC AudioFormat = "flac"
+	// AudioFormatOpus is Opus audio, supported for output
+	AudioFormatOpus AudioFormat = "opus"
+	// AudioFormatPCM16 is raw 16-bit little-endian PCM audio; required for streamed output
+	AudioFormatPCM16 AudioFormat = "pcm16"
+)
+
+// AudioOutputConfig configures the audio output of a request.
+type AudioOutputConfig struct {
+	// Voice is the voice the model uses (e.g. "alloy")
+	Voice string `json:"voice"`
+	// Format is the audio format of the output
+	Format AudioFormat `json:"format"`
+}
+
+// MessageAudio is the audio output of a model. In stream deltas, Data and
+// Transcript hold the next chunk of the audio and its transcript.
+type MessageAudio struct {
+	// ID identifies the audio; send it back with the assistant message in multi-turn conversations
+	ID string `json:"id,omitempty"`
+	// Data is the base64 encoded audio
+	Data string `json:"data,omitempty"`
+	// Transcript is the text of the audio
+	Transcript string `json:"transcript,omitempty"`
+	// ExpiresAt is the Unix time after which the audio can no longer be referenced by ID
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+}
+
+// Decode returns the audio bytes in the format requested with WithAudioOutput.
+func (a *MessageAudio) Decode() ([]byte, error) {
+	if a == nil || a.Data == "" {
+		return nil, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(a.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audio: %w", err)
+	}
+
+	return data, nil
+}
+
+// NewAudioPart creates an input audio content part from WAV or MP3 bytes.
+func NewAudioPart(data []byte, format AudioFormat) ContentPart {
+	return ContentPart{
+		Type: "input_audio",
+		InputAudio: &InputAudio{
+			Data:   base64.StdEncoding.EncodeToString(data),
+			Format: format,
+		},
+	}
+}
+
+// NewAudioPartFromPath creates an input audio content part from a .wav or .mp3 file.
+func NewAudioPartFromPath(path string) (ContentPart, error) {
+	var format AudioFormat
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		format = AudioFormatWAV
+	case ".mp3":
+		format = AudioFormatMP3
+	default:
+		return ContentPart{}, &ValidationError{
+			Field:   "path",
+			Message: fmt.Sprintf("unsupported audio file %s: expected .wav or .mp3", filepath.Base(path)),
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ContentPart{}, fmt.Errorf("failed to read audio file: %w", err)
+	}
+
+	return NewAudioPart(data, format), nil
+}
+
+// CreateAudioMessage creates a message with text followed by an audio content part.
+func CreateAudioMessage(role string, text string, audio ContentPart) Message {
+	return Message{
+		Role: role,
+		Content: []ContentPart{
+			{Type: "text", Text: text},
+			audio,
+		},
+	}
+}
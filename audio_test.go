@@ -0,0 +1,121 @@
+package openrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewAudioPart(t *testing.T) {
+	wav := []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+	part := NewAudioPart(wav, AudioFormatWAV)
+	if part.Type != "input_audio" || part.InputAudio == nil || part.InputAudio.Format != AudioFormatWAV {
+		t.Fatalf("unexpected audio part: %+v", part)
+	}
+	if part.InputAudio.Data != base64.StdEncoding.EncodeToString(wav) {
+		t.Errorf("expected plain base64 data, got %q", part.InputAudio.Data)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "question.MP3")
+	if err := os.WriteFile(path, []byte("ID3"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	part, err := NewAudioPartFromPath(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if part.InputAudio.Format != AudioFormatMP3 {
+		t.Errorf("expected mp3 format, got %q", part.InputAudio.Format)
+	}
+
+	if _, err := NewAudioPartFromPath(filepath.Join(dir, "question.ogg")); err == nil {
+		t.Error("expected error for unsupported format")
+	} else if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("expected ValidationError, got %T", err)
+	}
+}
+
+func TestChatCompleteAudioOutput(t *testing.T) {
+	audio := []byte("fake wav audio")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		if len(req.Modalities) != 2 || req.Modalities[0] != ModalityText || req.Modalities[1] != ModalityAudio {
+			t.Errorf("unexpected modalities: %v", req.Modalities)
+		}
+		if req.Audio == nil || req.Audio.Voice != "alloy" || req.Audio.Format != AudioFormatWAV {
+			t.Errorf("unexpected audio config: %+v", req.Audio)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ChatCompletionResponse{
+			ID: "chat-a",
+			Choices: []Choice{{Message: Message{
+				Role: "assistant",
+				Audio: &MessageAudio{
+					ID:         "audio_1",
+					Data:       base64.StdEncoding.EncodeToString(audio),
+					Transcript: "Hello there",
+				},
+			}}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.ChatComplete(context.Background(),
+		[]Message{CreateAudioMessage("user", "Answer the question", NewAudioPart([]byte("RIFF"), AudioFormatWAV))},
+		WithModel("openai/gpt-4o-audio-preview"),
+		WithAudioOutput("alloy", AudioFormatWAV),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := resp.Choices[0].Message.Audio
+	if out == nil || out.Transcript != "Hello there" {
+		t.Fatalf("unexpected audio output: %+v", out)
+	}
+	data, err := out.Decode()
+	if err != nil || !bytes.Equal(data, audio) {
+		t.Errorf("expected decoded audio %q, got %q (%v)", audio, data, err)
+	}
+}
+
+func TestChatStreamAccumulatorAudio(t *testing.T) {
+	chunks := [][]byte{[]byte("first "), []byte("second")}
+
+	acc := NewChatStreamAccumulator()
+	acc.Add(ChatCompletionResponse{Choices: []Choice{{Delta: &Message{Audio: &MessageAudio{
+		ID: "audio_1", Data: base64.StdEncoding.EncodeToString(chunks[0]), Transcript: "Hel",
+	}}}}})
+	acc.Add(ChatCompletionResponse{Choices: []Choice{{Delta: &Message{Audio: &MessageAudio{
+		Data: base64.StdEncoding.EncodeToString(chunks[1]), Transcript: "lo", ExpiresAt: 1700000000,
+	}}}}})
+
+	audio := acc.Response().Choices[0].Message.Audio
+	if audio == nil || audio.ID != "audio_1" || audio.Transcript != "Hello" || audio.ExpiresAt != 1700000000 {
+		t.Fatalf("unexpected accumulated audio: %+v", audio)
+	}
+	data, err := audio.Decode()
+	if err != nil || string(data) != "first second" {
+		t.Errorf("expected joined audio, got %q (%v)", data, err)
+	}
+
+	var empty *MessageAudio
+	if data, err := empty.Decode(); data != nil || err != nil {
+		t.Errorf("expected nil audio to decode to nothing, got %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,117 @@
+package openrouter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CacheControlEphemeral is the cache type for prompt caching breakpoints.
+const CacheControlEphemeral = "ephemeral"
+
+// NewCacheControl returns an ephemeral cache breakpoint with the provider's default lifetime.
+func NewCacheControl() *CacheControl {
+	return &CacheControl{Type: CacheControlEphemeral}
+}
+
+// CachedTextPart creates a text content part marked as a cache breakpoint. Providers
+// with explicit caching cache the prompt up to and including this part, so put large,
+// unchanging content (system prompts, documents) in it and keep it identical across
+// requests.
+func CachedTextPart(text string) ContentPart {
+	return ContentPart{
+		Type:         "text",
+		Text:         text,
+		CacheControl: NewCacheControl(),
+	}
+}
+
+// CreateCachedChatMessage creates a message whose first part is cached and is
+// followed by uncached text parts.
+//
+// Example:
+//
+//	messages := []openrouter.Message{
+//	    openrouter.CreateCachedSystemMessage(instructions),
+//	    openrouter.CreateCachedUserMessage(documents, "What does the contract say about renewal?"),
+//	}
+func CreateCachedChatMessage(role string, cached string, text ...string) Message {
+	parts := make([]ContentPart, 0, len(text)+1)
+	parts = append(parts, CachedTextPart(cached))
+	for _, t := range text {
+		parts = append(parts, ContentPart{Type: "text", Text: t})
+	}
+
+	return Message{
+		Role:    role,
+		Content: parts,
+	}
+}
+
+// CreateCachedSystemMessage creates a system message that is cached in full.
+func CreateCachedSystemMessage(content string) Message {
+	return CreateCachedChatMessage("system", content)
+}
+
+// CreateCachedUserMessage creates a user message with cached content, such as
+// retrieved documents, followed by uncached text, such as the question.
+func CreateCachedUserMessage(cached string, text ...string) Message {
+	return CreateCachedChatMessage("user", cached, text...)
+}
+
+// InputCacheReadPrice returns the price per token for prompt tokens read from the
+// cache. It returns false if the model does not support caching.
+func (p ModelPricing) InputCacheReadPrice() (float64, bool) {
+	return parsePrice(p.InputCacheRead)
+}
+
+// InputCacheWritePrice returns the price per token for prompt tokens written to the
+// cache. It returns false if the model does not charge for cache writes.
+func (p ModelPricing) InputCacheWritePrice() (float64, bool) {
+	return parsePrice(p.InputCacheWrite)
+}
+
+// CacheSavings estimates how much caching saved on a request with the given usage:
+// the discount on cache reads minus the premium paid for cache writes, compared to
+// the regular prompt price. The result is negative when writes cost more than reads saved.
+func (p ModelPricing) CacheSavings(usage Usage) float64 {
+	prompt, ok := parsePrice(&p.Prompt)
+	if !ok {
+		return 0
+	}
+
+	var savings float64
+	if read, ok := p.InputCacheReadPrice(); ok {
+		savings += float64(usage.CachedTokens()) * (prompt - read)
+	}
+	if write, ok := p.InputCacheWritePrice(); ok {
+		savings -= float64(usage.CacheWriteTokens()) * (write - prompt)
+	}
+
+	return savings
+}
+
+// InputCacheReadPrice returns the endpoint's price per token for prompt tokens read
+// from the cache. It returns false if the endpoint does not support caching.
+func (p ModelEndpointPricing) InputCacheReadPrice() (float64, bool) {
+	return parsePrice(p.InputCacheRead)
+}
+
+// InputCacheWritePrice returns the endpoint's price per token for prompt tokens
+// written to the cache. It returns false if the endpoint does not charge for cache writes.
+func (p ModelEndpointPricing) InputCacheWritePrice() (float64, bool) {
+	return parsePrice(p.InputCacheWrite)
+}
+
+// parsePrice parses a price string as returned by the API.
+func parsePrice(value *string) (float64, bool) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return 0, false
+	}
+
+	price, err := strconv.ParseFloat(strings.TrimSpace(*value), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return price, true
+}
@@ -0,0 +1,114 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateCachedMessages(t *testing.T) {
+	msg := CreateCachedSystemMessage("You are a contract analyst.")
+	parts, ok := msg.Content.([]ContentPart)
+	if msg.Role != "system" || !ok || len(parts) != 1 {
+		t.Fatalf("unexpected cached system message: %+v", msg)
+	}
+	if parts[0].CacheControl == nil || parts[0].CacheControl.Type != CacheControlEphemeral {
+		t.Errorf("expected ephemeral cache control, got %+v", parts[0].CacheControl)
+	}
+
+	msg = CreateCachedUserMessage("<documents>", "What does it say?")
+	parts, _ = msg.Content.([]ContentPart)
+	if msg.Role != "user" || len(parts) != 2 {
+		t.Fatalf("unexpected cached user message: %+v", msg)
+	}
+	if parts[0].Text != "<documents>" || parts[0].CacheControl == nil {
+		t.Errorf("expected cached document part, got %+v", parts[0])
+	}
+	if parts[1].Text != "What does it say?" || parts[1].CacheControl != nil {
+		t.Errorf("expected uncached question part, got %+v", parts[1])
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("failed to marshal message: %v", err)
+	}
+	if !strings.Contains(string(data), `"cache_control":{"type":"ephemeral"}`) {
+		t.Errorf("expected cache_control in JSON, got %s", data)
+	}
+	if strings.Count(string(data), "cache_control") != 1 {
+		t.Errorf("expected a single cache breakpoint, got %s", data)
+	}
+}
+
+func TestChatCompleteCacheUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		messages := req["messages"].([]interface{})
+		content := messages[0].(map[string]interface{})["content"].([]interface{})
+		part := content[0].(map[string]interface{})
+		if cc, _ := part["cache_control"].(map[string]interface{}); cc["type"] != "ephemeral" {
+			t.Errorf("expected cache_control to be sent, got %v", part)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chat-c","choices":[{"message":{"role":"assistant","content":"ok"}}],` +
+			`"usage":{"prompt_tokens":30010,"completion_tokens":2,"total_tokens":30012,` +
+			`"prompt_tokens_details":{"cached_tokens":28000,"cache_write_tokens":2000}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.ChatComplete(context.Background(), []Message{
+		CreateCachedSystemMessage(strings.Repeat("context ", 100)),
+		CreateUserMessage("Question"),
+	}, WithModel("anthropic/claude-sonnet-4"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Usage.CachedTokens() != 28000 {
+		t.Errorf("expected 28000 cached tokens, got %d", resp.Usage.CachedTokens())
+	}
+	if resp.Usage.CacheWriteTokens() != 2000 {
+		t.Errorf("expected 2000 cache write tokens, got %d", resp.Usage.CacheWriteTokens())
+	}
+}
+
+func TestModelPricingCache(t *testing.T) {
+	read, write := "0.0000003", "0.00000375"
+	pricing := ModelPricing{Prompt: "0.000003", InputCacheRead: &read, InputCacheWrite: &write}
+
+	if price, ok := pricing.InputCacheReadPrice(); !ok || price != 0.0000003 {
+		t.Errorf("unexpected cache read price %v (%v)", price, ok)
+	}
+	if price, ok := pricing.InputCacheWritePrice(); !ok || price != 0.00000375 {
+		t.Errorf("unexpected cache write price %v (%v)", price, ok)
+	}
+
+	usage := Usage{PromptTokensDetails: &PromptTokensDetails{CachedTokens: 10000, CacheWriteTokens: 1000}}
+	// 10000 * (3e-6 - 0.3e-6) - 1000 * (3.75e-6 - 3e-6) = 0.027 - 0.00075
+	if savings := pricing.CacheSavings(usage); math.Abs(savings-0.02625) > 1e-9 {
+		t.Errorf("expected savings 0.02625, got %v", savings)
+	}
+
+	if _, ok := (ModelPricing{Prompt: "0.000001"}).InputCacheReadPrice(); ok {
+		t.Error("expected no cache read price without caching support")
+	}
+	if savings := (ModelPricing{Prompt: "0.000001"}).CacheSavings(usage); savings != 0 {
+		t.Errorf("expected no savings without caching support, got %v", savings)
+	}
+
+	endpoint := ModelEndpointPricing{InputCacheRead: &read}
+	if price, ok := endpoint.InputCacheReadPrice(); !ok || price != 0.0000003 {
+		t.Errorf("unexpected endpoint cache read price %v (%v)", price, ok)
+	}
+}
@@ -23,6 +23,9 @@ func (c *Client) ChatComplete(ctx context.Context, messages []Message, opts ...C
 	for _, opt := range opts {
 		opt(req)
 	}
+	if req.optionErr != nil {
+		return nil, req.optionErr
+	}
 
 	// Handle model suffixes
 	req.Model = processModelSuffix(req.Model, req)
@@ -32,13 +35,28 @@ func (c *Client) ChatComplete(ctx context.Context, messages []Message, opts ...C
 		return nil, ErrNoModel
 	}
 
+	// Apply per-request retry configuration
+	if req.retryConfig != nil {
+		ctx = ContextWithRetryConfig(ctx, req.retryConfig)
+	}
+
 	// Make request
 	var resp ChatCompletionResponse
-	err := c.doRequest(ctx, "POST", "/chat/completions", req, &resp)
+	var err error
+	if req.hedge != nil {
+		err = c.hedgeChatComplete(ctx, req, &resp)
+	} else {
+		err = c.doRequest(ctx, "POST", "/chat/completions", req, &resp)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	// Fetch generation stats if requested; a failure does not fail the request
+	if req.generationStats {
+		resp.Generation, resp.GenerationErr = c.attachGenerationStats(ctx, resp.ID)
+	}
+
 	return &resp, nil
 }
 
@@ -61,6 +79,9 @@ func (c *Client) ChatCompleteStream(ctx context.Context, messages []Message, opt
 	for _, opt := range opts {
 		opt(req)
 	}
+	if req.optionErr != nil {
+		return nil, req.optionErr
+	}
 
 	// Handle model suffixes
 	req.Model = processModelSuffix(req.Model, req)
@@ -71,7 +92,13 @@ func (c *Client) ChatCompleteStream(ctx context.Context, messages []Message, opt
 	}
 
 	// Create stream
-	stream, err := c.createStream(ctx, "/chat/completions", req)
+	var stream *eventStream
+	var err error
+	if req.hedge != nil {
+		stream, err = c.hedgeStream(ctx, "/chat/completions", req)
+	} else {
+		stream, err = c.createStream(ctx, "/chat/completions", req)
+	}
 	if err != nil {
 		return nil, err
 	}
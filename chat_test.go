@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -474,3 +475,149 @@ func TestChatCompleteStream(t *testing.T) {
 		t.Error("expected to receive at least one event")
 	}
 }
+
+func TestChatStreamMidStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"id":"chat-123","provider":"Together","choices":[{"index":0,"delta":{"content":"Hel"}}]}` + "\n\n"))
+		w.Write([]byte(`data: {"id":"chat-123","provider":"Together","error":{"code":"server_error","message":"Provider disconnected unexpectedly"},"choices":[{"index":0,"delta":{"content":""},"finish_reason":"error"}]}` + "\n\n"))
+		// Events after the error are not read
+		w.Write([]byte(`data: {"id":"chat-123","choices":[{"index":0,"delta":{"content":"lo"}}]}` + "\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("Hello")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	acc := NewChatStreamAccumulator()
+	for chunk := range stream.Events() {
+		acc.Add(chunk)
+	}
+
+	err = stream.Err()
+	if _, ok := IsStreamError(err); !ok {
+		t.Fatalf("expected a stream error, got %v", err)
+	}
+	reqErr, ok := IsRequestError(err)
+	if !ok {
+		t.Fatalf("expected the stream error to wrap a request error, got %v", err)
+	}
+	if reqErr.Code != "server_error" || reqErr.Message != "Provider disconnected unexpectedly" || reqErr.ProviderName() != "Together" {
+		t.Errorf("unexpected request error: %+v", reqErr)
+	}
+
+	resp := acc.Response()
+	if resp.Choices[0].Message.Content != "Hel" || resp.Choices[0].FinishReason != "error" {
+		t.Errorf("expected the chunks up to the error, got %+v", resp.Choices[0])
+	}
+}
+
+func TestChatCompleteUsageAccounting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		if req.Usage == nil || !req.Usage.Include {
+			t.Error("expected usage.include to be true")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "chat-123",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi"}, "finish_reason": "stop"}],
+			"usage": {
+				"prompt_tokens": 194,
+				"completion_tokens": 2,
+				"total_tokens": 196,
+				"cost": 0.95,
+				"is_byok": true,
+				"prompt_tokens_details": {"cached_tokens": 128},
+				"completion_tokens_details": {"reasoning_tokens": 1},
+				"cost_details": {"upstream_inference_cost": 19}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("Hello")},
+		WithModel("openai/gpt-4o"),
+		WithUsageAccounting(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	usage := resp.Usage
+	if usage.Cost != 0.95 || !usage.IsBYOK {
+		t.Errorf("unexpected cost accounting: %+v", usage)
+	}
+	if usage.CachedTokens() != 128 {
+		t.Errorf("expected 128 cached tokens, got %d", usage.CachedTokens())
+	}
+	if usage.ReasoningTokens() != 1 {
+		t.Errorf("expected 1 reasoning token, got %d", usage.ReasoningTokens())
+	}
+	if usage.CostDetails == nil || usage.CostDetails.UpstreamInferenceCost != 19 {
+		t.Errorf("unexpected cost details: %+v", usage.CostDetails)
+	}
+
+	// Without accounting, nothing is sent and the helpers return zero
+	req := &ChatCompletionRequest{}
+	data, _ := json.Marshal(req)
+	if strings.Contains(string(data), `"usage"`) {
+		t.Errorf("expected usage to be omitted by default, got %s", data)
+	}
+	if (Usage{}).CachedTokens() != 0 || (Usage{}).ReasoningTokens() != 0 {
+		t.Error("expected zero token details without accounting")
+	}
+}
+
+func TestChatStreamUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"id":"chat-123","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n"))
+		w.Write([]byte(`data: {"id":"chat-123","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}` + "\n\n"))
+		w.Write([]byte(`data: {"id":"chat-123","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":1,"total_tokens":11,"cost":0.0002}}` + "\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("Hello")},
+		WithModel("openai/gpt-4o"),
+		WithUsageAccounting(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	if stream.Usage() != nil {
+		t.Error("expected no usage before the final chunk")
+	}
+
+	for range stream.Events() {
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	usage := stream.Usage()
+	if usage == nil {
+		t.Fatal("expected usage from the final chunk")
+	}
+	if usage.TotalTokens != 11 || usage.Cost != 0.0002 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
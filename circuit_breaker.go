@@ -0,0 +1,508 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCircuitFailureThreshold is the number of consecutive failures that opens a circuit.
+	defaultCircuitFailureThreshold = 5
+	// defaultCircuitCooldown is how long a circuit stays open before letting a trial request through.
+	defaultCircuitCooldown = 30 * time.Second
+)
+
+// CircuitState is the state of a circuit of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial request through to decide whether to close again
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitStateChange describes a circuit changing state. Exactly one of Model and
+// Provider is set.
+type CircuitStateChange struct {
+	// Model is the model of a model circuit
+	Model string
+	// Provider is the provider name of a provider circuit
+	Provider string
+	From     CircuitState
+	To       CircuitState
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens a circuit. Defaults to 5.
+	FailureThreshold int
+	// Cooldown is how long a circuit stays open before a trial request is let through. Defaults to 30s.
+	Cooldown time.Duration
+	// IgnoreOpenProviders adds providers with an open circuit to Provider.Ignore of
+	// chat and completion requests, so that they are routed elsewhere. Without it,
+	// provider circuits only fail requests that are restricted to open providers.
+	IgnoreOpenProviders bool
+	// IsFailure reports whether an error counts as a failure. Defaults to server
+	// errors, timeouts and network errors. Only successful requests count as
+	// successes; other errors, such as rate limits, leave the circuit unchanged.
+	IsFailure func(err error) bool
+	// OnStateChange is called when a circuit changes state.
+	OnStateChange func(change CircuitStateChange)
+}
+
+// CircuitBreaker tracks failures of chat and completion requests per model and per
+// provider, and fails requests fast while a model or provider keeps failing. A circuit
+// opens after FailureThreshold consecutive failures and fails requests with a
+// *CircuitOpenError; after Cooldown it lets a single trial request through, which
+// closes the circuit if it succeeds and opens it again if it fails.
+//
+// Provider circuits track the providers named in error metadata and in the provider
+// field of responses. A CircuitBreaker is safe for concurrent use and may be shared
+// between clients.
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	models    map[string]*circuit
+	providers map[string]*circuit
+}
+
+// circuit is the state of a single model or provider.
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// probing is set while the trial request of a half-open circuit is in flight
+	probing bool
+}
+
+// NewCircuitBreaker creates a circuit breaker with all circuits closed.
+//
+// Example:
+//
+//	breaker := openrouter.NewCircuitBreaker(openrouter.CircuitBreakerConfig{
+//	    FailureThreshold:    3,
+//	    Cooldown:            time.Minute,
+//	    IgnoreOpenProviders: true,
+//	})
+//	client := openrouter.NewClient(
+//	    openrouter.WithAPIKey(apiKey),
+//	    openrouter.WithCircuitBreaker(breaker),
+//	)
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = defaultCircuitFailureThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultCircuitCooldown
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isCircuitFailure
+	}
+
+	return &CircuitBreaker{
+		config:    config,
+		now:       time.Now,
+		models:    make(map[string]*circuit),
+		providers: make(map[string]*circuit),
+	}
+}
+
+// WithCircuitBreaker checks chat and completion requests, including streams, against
+// the circuit breaker. The breaker sits inside the middleware chain, so each retry
+// attempt is checked and recorded; once a circuit opens, the remaining retries fail fast.
+// Streams are recorded when they end, so a provider failing mid-stream counts as a
+// failure, and a stream closed before its end counts as neither.
+func WithCircuitBreaker(breaker *CircuitBreaker) ClientOption {
+	return func(c *Client) {
+		c.circuitBreaker = breaker
+	}
+}
+
+// ModelState returns the state of the circuit of a model.
+func (b *CircuitBreaker) ModelState(model string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state(b.models[model])
+}
+
+// ProviderState returns the state of the circuit of a provider.
+func (b *CircuitBreaker) ProviderState(provider string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state(b.providers[provider])
+}
+
+// Reset closes every circuit.
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.models = make(map[string]*circuit)
+	b.providers = make(map[string]*circuit)
+}
+
+// state returns the state of a circuit as of now. Open circuits whose cooldown has
+// passed are reported as half-open.
+func (b *CircuitBreaker) state(c *circuit) CircuitState {
+	if c == nil {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && b.now().Sub(c.openedAt) >= b.config.Cooldown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// admission records the trial requests a request was admitted as, so that its
+// outcome can be recorded.
+type admission struct {
+	model      string
+	modelProbe bool
+	probes     []string
+	ignore     []string
+}
+
+// admit decides whether a request for model with the given routing may be sent.
+func (b *CircuitBreaker) admit(model string, provider *Provider) (*admission, []CircuitStateChange, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var changes []CircuitStateChange
+	adm := &admission{model: model}
+
+	if c := b.models[model]; c != nil {
+		allowed, probe, change := b.tryCircuit(c)
+		if change != nil {
+			change.Model = model
+			changes = append(changes, *change)
+		}
+		if !allowed {
+			return nil, changes, &CircuitOpenError{Model: model, RetryAfter: b.retryAfter(c)}
+		}
+		adm.modelProbe = probe
+	}
+
+	if b.config.IgnoreOpenProviders {
+		for _, name := range b.sortedProviders() {
+			c := b.providers[name]
+			if c.state == CircuitClosed {
+				continue
+			}
+
+			allowed, probe, change := b.tryCircuit(c)
+			if change != nil {
+				change.Provider = name
+				changes = append(changes, *change)
+			}
+			if probe {
+				adm.probes = append(adm.probes, name)
+			} else if !allowed {
+				adm.ignore = append(adm.ignore, name)
+			}
+		}
+		return adm, changes, nil
+	}
+
+	// Without rewriting, only requests restricted to open providers are failed
+	pinned := pinnedProviders(provider)
+	if len(pinned) == 0 {
+		return adm, changes, nil
+	}
+	for _, name := range pinned {
+		if c := b.providers[name]; c == nil || c.state == CircuitClosed {
+			return adm, changes, nil
+		}
+	}
+	for _, name := range pinned {
+		c := b.providers[name]
+		allowed, probe, change := b.tryCircuit(c)
+		if change != nil {
+			change.Provider = name
+			changes = append(changes, *change)
+		}
+		if allowed {
+			if probe {
+				adm.probes = append(adm.probes, name)
+			}
+			return adm, changes, nil
+		}
+	}
+
+	b.release(adm)
+	c := b.providers[pinned[0]]
+	return nil, changes, &CircuitOpenError{Model: model, Provider: pinned[0], RetryAfter: b.retryAfter(c)}
+}
+
+// tryCircuit reports whether a request may pass a circuit and whether it is the
+// circuit's trial request. An open circuit whose cooldown has passed becomes half-open.
+func (b *CircuitBreaker) tryCircuit(c *circuit) (allowed, probe bool, change *CircuitStateChange) {
+	if c.state == CircuitOpen && b.now().Sub(c.openedAt) >= b.config.Cooldown {
+		change = &CircuitStateChange{From: CircuitOpen, To: CircuitHalfOpen}
+		c.state = CircuitHalfOpen
+	}
+
+	switch c.state {
+	case CircuitOpen:
+		return false, false, change
+	case CircuitHalfOpen:
+		if c.probing {
+			return false, false, change
+		}
+		c.probing = true
+		return true, true, change
+	}
+	return true, false, change
+}
+
+// retryAfter returns how long until an open circuit lets a trial request through.
+func (b *CircuitBreaker) retryAfter(c *circuit) time.Duration {
+	if c == nil || c.state != CircuitOpen {
+		return 0
+	}
+	return max(0, b.config.Cooldown-b.now().Sub(c.openedAt))
+}
+
+// sortedProviders returns the names of the provider circuits in a stable order.
+func (b *CircuitBreaker) sortedProviders() []string {
+	names := make([]string, 0, len(b.providers))
+	for name := range b.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// release gives up the trial requests of an admission without recording an outcome.
+func (b *CircuitBreaker) release(adm *admission) {
+	if adm.modelProbe {
+		if c := b.models[adm.model]; c != nil {
+			c.probing = false
+		}
+	}
+	for _, name := range adm.probes {
+		if c := b.providers[name]; c != nil {
+			c.probing = false
+		}
+	}
+}
+
+// record records the outcome of an admitted request. Provider is the provider that
+// served or failed the request, if known; abandoned reports whether the caller gave
+// up on the request.
+func (b *CircuitBreaker) record(adm *admission, provider string, err error, abandoned bool) []CircuitStateChange {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	defer b.release(adm)
+
+	// Only successes and failures change a circuit. Requests abandoned by the caller
+	// and errors that are not failures, such as rate limits, say nothing about
+	// whether the model or provider has recovered
+	if abandoned || (err != nil && !b.config.IsFailure(err)) {
+		return nil
+	}
+
+	failed := err != nil
+	var changes []CircuitStateChange
+
+	if change := b.update(b.models, adm.model, failed); change != nil {
+		change.Model = adm.model
+		changes = append(changes, *change)
+	}
+	if provider != "" {
+		if change := b.update(b.providers, provider, failed); change != nil {
+			change.Provider = provider
+			changes = append(changes, *change)
+		}
+	}
+
+	return changes
+}
+
+// update records a success or failure on the circuit for key.
+func (b *CircuitBreaker) update(circuits map[string]*circuit, key string, failed bool) *CircuitStateChange {
+	c := circuits[key]
+	if c == nil {
+		if !failed {
+			return nil
+		}
+		c = &circuit{}
+		circuits[key] = c
+	}
+
+	from := c.state
+	if failed {
+		c.failures++
+		if c.state == CircuitHalfOpen || (c.state == CircuitClosed && c.failures >= b.config.FailureThreshold) {
+			c.state = CircuitOpen
+			c.openedAt = b.now()
+		}
+	} else {
+		c.state = CircuitClosed
+		c.failures = 0
+	}
+
+	if c.state == from {
+		return nil
+	}
+	return &CircuitStateChange{From: from, To: c.state}
+}
+
+// notify reports state changes to the configured callback.
+func (b *CircuitBreaker) notify(changes []CircuitStateChange) {
+	if b.config.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.config.OnStateChange(change)
+	}
+}
+
+// breakCircuits returns a RoundTripFunc that checks chat and completion requests
+// against the client's circuit breaker before calling next.
+func (b *CircuitBreaker) breakCircuits(next RoundTripFunc) RoundTripFunc {
+	return func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+		model, provider, ok := requestRouting(req.Body)
+		if !ok || model == "" {
+			return next(ctx, req)
+		}
+
+		adm, changes, err := b.admit(model, provider)
+		b.notify(changes)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(adm.ignore) > 0 {
+			req.Body = withIgnoredProviders(req.Body, adm.ignore)
+		}
+
+		resp, err := next(ctx, req)
+
+		// A stream can still fail after it opened, so it is recorded when it ends
+		if end := streamEndFromContext(ctx); end != nil && req.Stream && err == nil {
+			end.report = func(provider string, err error, abandoned bool) {
+				if failedProvider := responseProvider(nil, err); failedProvider != "" {
+					provider = failedProvider
+				}
+				b.notify(b.record(adm, provider, err, abandoned))
+			}
+			return resp, nil
+		}
+
+		b.notify(b.record(adm, responseProvider(resp, err), err, err != nil && ctx.Err() != nil))
+
+		return resp, err
+	}
+}
+
+// requestRouting returns the model and provider routing of chat and completion requests.
+func requestRouting(body interface{}) (string, *Provider, bool) {
+	switch r := body.(type) {
+	case *ChatCompletionRequest:
+		return r.Model, r.Provider, true
+	case *CompletionRequest:
+		return r.Model, r.Provider, true
+	}
+	return "", nil, false
+}
+
+// pinnedProviders returns the providers a request is restricted to, if any.
+func pinnedProviders(provider *Provider) []string {
+	if provider == nil {
+		return nil
+	}
+	if len(provider.Only) > 0 {
+		return provider.Only
+	}
+	if provider.AllowFallbacks != nil && !*provider.AllowFallbacks {
+		return provider.Order
+	}
+	return nil
+}
+
+// withIgnoredProviders returns a copy of a chat or completion request that also
+// ignores the given providers. The caller's request is left unchanged.
+func withIgnoredProviders(body interface{}, names []string) interface{} {
+	ignore := func(provider *Provider) *Provider {
+		var p Provider
+		if provider != nil {
+			p = *provider
+		}
+		p.Ignore = slices.Clone(p.Ignore)
+		for _, name := range names {
+			if !slices.Contains(p.Ignore, name) {
+				p.Ignore = append(p.Ignore, name)
+			}
+		}
+		return &p
+	}
+
+	switch r := body.(type) {
+	case *ChatCompletionRequest:
+		rewritten := *r
+		rewritten.Provider = ignore(r.Provider)
+		return &rewritten
+	case *CompletionRequest:
+		rewritten := *r
+		rewritten.Provider = ignore(r.Provider)
+		return &rewritten
+	}
+	return body
+}
+
+// responseProvider returns the provider that served or failed a request, if known.
+func responseProvider(resp *RoundTripResponse, err error) string {
+	if err != nil {
+		if reqErr, ok := IsRequestError(err); ok {
+			return reqErr.ProviderName()
+		}
+		return ""
+	}
+	if resp == nil {
+		return ""
+	}
+
+	switch r := resp.Result.(type) {
+	case *ChatCompletionResponse:
+		return r.Provider
+	case *CompletionResponse:
+		return r.Provider
+	}
+	return ""
+}
+
+// isCircuitFailure reports whether an error suggests the model or provider is
+// unavailable: server errors, request timeouts and network errors. Other client
+// errors, including rate limits, do not count.
+func isCircuitFailure(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	if _, ok := IsValidationError(err); ok {
+		return false
+	}
+	if reqErr, ok := IsRequestError(err); ok {
+		return reqErr.IsServerError() || reqErr.StatusCode == 408
+	}
+	return true
+}
@@ -0,0 +1,381 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// providerOutageServer fails requests routed to a provider in down with a provider
+// error, and answers the others from the provider "healthy".
+func providerOutageServer(t *testing.T, down *sync.Map, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, failing := down.Load("flaky"); failing && (req.Provider == nil || !slices.Contains(req.Provider.Ignore, "flaky")) {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"error":{"message":"Provider returned error","code":"502","metadata":{"provider_name":"flaky","raw":"upstream down"}}}`))
+			return
+		}
+
+		resp := finalResponse("ok")
+		resp.Provider = "healthy"
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestCircuitBreakerOpensAndFailsFast(t *testing.T) {
+	var down sync.Map
+	down.Store("flaky", true)
+	var requests atomic.Int32
+	server := providerOutageServer(t, &down, &requests)
+	defer server.Close()
+
+	var changes []CircuitStateChange
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		Cooldown:         time.Hour,
+		OnStateChange: func(change CircuitStateChange) {
+			changes = append(changes, change)
+		},
+	})
+	client := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetryConfig(&RetryConfig{MaxRetries: 5, InitialDelay: time.Millisecond}),
+		WithCircuitBreaker(breaker),
+	)
+
+	_, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the retries to stop at the open circuit, got %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("expected 2 requests before the circuit opened, got %d", got)
+	}
+
+	circuitErr, ok := IsCircuitOpenError(err)
+	if !ok || circuitErr.Model != "test-model" || circuitErr.Provider != "" || circuitErr.RetryAfter <= 0 {
+		t.Errorf("unexpected circuit error: %+v", circuitErr)
+	}
+	if breaker.ModelState("test-model") != CircuitOpen || breaker.ProviderState("flaky") != CircuitOpen {
+		t.Errorf("expected the model and provider circuits to be open, got %v and %v",
+			breaker.ModelState("test-model"), breaker.ProviderState("flaky"))
+	}
+	if len(changes) != 2 || changes[0].Model != "test-model" || changes[1].Provider != "flaky" || changes[0].To != CircuitOpen {
+		t.Errorf("unexpected state changes: %+v", changes)
+	}
+
+	// Other models are unaffected by the model circuit
+	if breaker.ModelState("other-model") != CircuitClosed {
+		t.Errorf("expected other models to be closed")
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	var down sync.Map
+	down.Store("flaky", true)
+	var requests atomic.Int32
+	server := providerOutageServer(t, &down, &requests)
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithRetry(0, 0), WithCircuitBreaker(breaker))
+	send := func() error {
+		_, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+		return err
+	}
+
+	if err := send(); err == nil || breaker.ModelState("test-model") != CircuitOpen {
+		t.Fatalf("expected the failure to open the circuit, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if breaker.ModelState("test-model") != CircuitHalfOpen {
+		t.Fatalf("expected the circuit to be half-open after the cooldown")
+	}
+
+	// A failed trial request opens the circuit again
+	if err := send(); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the trial request to be sent, got %v", err)
+	}
+	if breaker.ModelState("test-model") != CircuitOpen {
+		t.Fatalf("expected the failed trial to open the circuit, got %v", breaker.ModelState("test-model"))
+	}
+
+	// A successful trial request closes it
+	down.Delete("flaky")
+	now = now.Add(time.Minute)
+	if err := send(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if breaker.ModelState("test-model") != CircuitClosed {
+		t.Errorf("expected the successful trial to close the circuit, got %v", breaker.ModelState("test-model"))
+	}
+}
+
+func TestCircuitBreakerHalfOpenAllowsSingleTrial(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	adm, _, err := breaker.admit("test-model", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	breaker.record(adm, "", &RequestError{StatusCode: 500}, false)
+
+	now = now.Add(time.Minute)
+	trial, _, err := breaker.admit("test-model", nil)
+	if err != nil || !trial.modelProbe {
+		t.Fatalf("expected a trial request, got %+v, %v", trial, err)
+	}
+
+	_, _, err = breaker.admit("test-model", nil)
+	if circuitErr, ok := IsCircuitOpenError(err); !ok || circuitErr.RetryAfter != 0 {
+		t.Fatalf("expected concurrent requests to fail while the trial is in flight, got %v", err)
+	}
+
+	// A trial abandoned by the caller lets the next request through
+	breaker.record(trial, "", context.Canceled, true)
+	if _, _, err := breaker.admit("test-model", nil); err != nil {
+		t.Errorf("expected a new trial request after the abandoned one, got %v", err)
+	}
+}
+
+func TestCircuitBreakerNonFailuresAreNotSuccesses(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Alternate server errors and rate limits
+		if requests.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"message":"unavailable"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, Cooldown: time.Minute})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithRetry(0, 0), WithCircuitBreaker(breaker))
+	send := func() error {
+		_, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+		return err
+	}
+
+	// 503, 429, 503, 429, 503: the rate limits do not reset the failure count
+	for i := 0; i < 5; i++ {
+		if err := send(); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("request %d: circuit opened early", i+1)
+		}
+	}
+	if breaker.ModelState("test-model") != CircuitOpen {
+		t.Fatalf("expected three interleaved server errors to open the circuit, got %v", breaker.ModelState("test-model"))
+	}
+
+	// A rate limited trial request leaves the circuit half-open
+	now = now.Add(time.Minute)
+	requests.Store(1)
+	if _, ok := IsRequestError(send()); !ok {
+		t.Fatal("expected the trial request to be sent")
+	}
+	if breaker.ModelState("test-model") != CircuitHalfOpen {
+		t.Errorf("expected a rate limited trial not to close the circuit, got %v", breaker.ModelState("test-model"))
+	}
+	if _, ok := IsRequestError(send()); !ok {
+		t.Fatal("expected another trial request after the rate limited one")
+	}
+	if breaker.ModelState("test-model") != CircuitOpen {
+		t.Errorf("expected a failed trial to open the circuit, got %v", breaker.ModelState("test-model"))
+	}
+}
+
+func TestCircuitBreakerRecordsStreamsWhenTheyEnd(t *testing.T) {
+	var mode atomic.Value
+	mode.Store("fail")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"id":"s","provider":"Bad","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+
+		switch mode.Load() {
+		case "fail":
+			w.Write([]byte(`data: {"id":"s","provider":"Bad","error":{"code":502,"message":"Provider disconnected"},"choices":[{"index":0,"delta":{},"finish_reason":"error"}]}` + "\n\n"))
+		case "hang":
+			<-r.Context().Done()
+		default:
+			w.Write([]byte("data: [DONE]\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, Cooldown: time.Minute})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithCircuitBreaker(breaker))
+	open := func() *ChatStream {
+		stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return stream
+	}
+
+	// Streams that fail mid-stream count as failures of the model and the provider
+	for i := 0; i < 3; i++ {
+		stream := open()
+		for range stream.Events() {
+		}
+		if _, ok := IsStreamError(stream.Err()); !ok {
+			t.Fatalf("expected the mid-stream error, got %v", stream.Err())
+		}
+	}
+	if breaker.ModelState("test-model") != CircuitOpen || breaker.ProviderState("Bad") != CircuitOpen {
+		t.Fatalf("expected failed streams to open the circuits, got model %v, provider %v",
+			breaker.ModelState("test-model"), breaker.ProviderState("Bad"))
+	}
+
+	// A stream closed before its end leaves the circuits half-open
+	now = now.Add(time.Minute)
+	mode.Store("hang")
+	stream := open()
+	<-stream.Events()
+	stream.Close()
+	if breaker.ModelState("test-model") != CircuitHalfOpen || breaker.ProviderState("Bad") != CircuitHalfOpen {
+		t.Fatalf("expected an abandoned stream not to change the circuits, got model %v, provider %v",
+			breaker.ModelState("test-model"), breaker.ProviderState("Bad"))
+	}
+
+	// A stream that completes closes both circuits
+	mode.Store("ok")
+	stream = open()
+	for range stream.Events() {
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if breaker.ModelState("test-model") != CircuitClosed || breaker.ProviderState("Bad") != CircuitClosed {
+		t.Errorf("expected a successful stream to close the circuits, got model %v, provider %v",
+			breaker.ModelState("test-model"), breaker.ProviderState("Bad"))
+	}
+}
+
+func TestCircuitBreakerIgnoresOpenProviders(t *testing.T) {
+	var down sync.Map
+	down.Store("flaky", true)
+	var requests atomic.Int32
+	server := providerOutageServer(t, &down, &requests)
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold:    1,
+		Cooldown:            time.Hour,
+		IgnoreOpenProviders: true,
+	})
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithRetry(0, 0), WithCircuitBreaker(breaker))
+
+	// The first request fails and opens both circuits; a different model then
+	// avoids the open provider instead of failing
+	_, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if _, ok := IsRequestError(err); !ok {
+		t.Fatalf("expected the provider error, got %v", err)
+	}
+
+	provider := Provider{Ignore: []string{"other"}}
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("other-model"),
+		WithProvider(provider),
+	)
+	if err != nil {
+		t.Fatalf("expected the request to avoid the open provider, got %v", err)
+	}
+	if resp.Provider != "healthy" {
+		t.Errorf("unexpected provider: %s", resp.Provider)
+	}
+	if len(provider.Ignore) != 1 {
+		t.Errorf("expected the caller's provider settings to be unchanged, got %v", provider.Ignore)
+	}
+	if breaker.ProviderState("flaky") != CircuitOpen {
+		t.Errorf("expected the provider circuit to stay open, got %v", breaker.ProviderState("flaky"))
+	}
+}
+
+func TestCircuitBreakerPinnedProviders(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{Cooldown: time.Hour})
+	breaker.providers["flaky"] = &circuit{state: CircuitOpen, openedAt: time.Now()}
+
+	noFallbacks := false
+	tests := []struct {
+		name     string
+		provider *Provider
+		open     bool
+	}{
+		{"no routing", nil, false},
+		{"preferred order", &Provider{Order: []string{"flaky"}}, false},
+		{"order without fallbacks", &Provider{Order: []string{"flaky"}, AllowFallbacks: &noFallbacks}, true},
+		{"only open providers", &Provider{Only: []string{"flaky"}}, true},
+		{"only with a closed provider", &Provider{Only: []string{"flaky", "healthy"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := breaker.admit("test-model", tt.provider)
+			circuitErr, ok := IsCircuitOpenError(err)
+			if ok != tt.open {
+				t.Fatalf("expected open=%v, got %v", tt.open, err)
+			}
+			if ok && circuitErr.Provider != "flaky" {
+				t.Errorf("unexpected provider: %s", circuitErr.Provider)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &RequestError{StatusCode: 503}, true},
+		{"request timeout", &RequestError{StatusCode: 408}, true},
+		{"network error", errors.New("connection refused"), true},
+		{"bad request", &RequestError{StatusCode: 400}, false},
+		{"rate limit", &RequestError{StatusCode: 429}, false},
+		{"validation error", ErrNoModel, false},
+		{"open circuit", &CircuitOpenError{Model: "m"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCircuitFailure(tt.err); got != tt.want {
+				t.Errorf("isCircuitFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,11 +2,10 @@ package openrouter
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,7 +26,30 @@ type Client struct {
 	appName       string
 	maxRetries    int
 	retryDelay    time.Duration
+	retryConfig   *RetryConfig
 	customHeaders map[string]string
+
+	// Most recent rate limit state reported by the API
+	rateLimitMu sync.RWMutex
+	rateLimit   *RateLimitInfo
+
+	// Client-side throttling
+	limiterMu    sync.RWMutex
+	rateLimiter  Limiter
+	tokenLimiter *TokenRateLimiter
+
+	// Middleware applied to every request, outermost first
+	middleware []Middleware
+
+	// Fails requests fast for failing models and providers
+	circuitBreaker *CircuitBreaker
+
+	// Tracing and metrics hooks
+	instrumentation Instrumentation
+
+	// Logging
+	logger       *slog.Logger
+	logBodyLimit int
 }
 
 // NewClient creates a new OpenRouter API client.
@@ -52,87 +74,27 @@ func NewClient(opts ...ClientOption) *Client {
 }
 
 // doRequestOnce performs a single HTTP request to the OpenRouter API without retry logic.
+// The request passes through the client's middleware chain.
 func (c *Client) doRequestOnce(ctx context.Context, method, endpoint string, body interface{}, v interface{}) error {
-	url := c.baseURL + endpoint
-
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = strings.NewReader(string(jsonData))
+	req := &RoundTripRequest{
+		Method:   method,
+		Endpoint: endpoint,
+		Body:     body,
+		Header:   c.requestHeaders(body, false),
+		result:   v,
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	resp, err := c.roundTrip(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	if c.referer != "" {
-		req.Header.Set("HTTP-Referer", c.referer)
+		return err
 	}
 
-	if c.appName != "" {
-		req.Header.Set("X-Title", c.appName)
+	if err := copyResult(resp, v); err != nil {
+		return err
 	}
 
-	// Add custom headers
-	for key, value := range c.customHeaders {
-		req.Header.Set(key, value)
-	}
-
-	// Add metadata headers if present
-	if reqStruct, ok := body.(interface{ GetMetadata() map[string]interface{} }); ok {
-		if metadata := reqStruct.GetMetadata(); metadata != nil {
-			for key, value := range metadata {
-				headerKey := "X-" + key
-				if strValue, ok := value.(string); ok {
-					req.Header.Set(headerKey, strValue)
-				}
-			}
-		}
-	}
-
-	// Perform request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Check for errors
-	if resp.StatusCode >= 400 {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errorResp); err != nil {
-			return &RequestError{
-				StatusCode: resp.StatusCode,
-				Message:    string(respBody),
-			}
-		}
-		return &RequestError{
-			StatusCode: resp.StatusCode,
-			Message:    errorResp.Error.Message,
-			Type:       errorResp.Error.Type,
-			Code:       errorResp.Error.Code,
-		}
-	}
-
-	// Unmarshal response
-	if v != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, v); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
-		}
+	if reporter, ok := v.(usageReporter); ok {
+		c.recordUsage(reporter.tokenUsage())
 	}
 
 	return nil
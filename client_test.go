@@ -351,3 +351,140 @@ func TestContextCancellation(t *testing.T) {
 		t.Errorf("expected DeadlineExceeded error, got %v", ctx.Err())
 	}
 }
+
+func TestWithRetryConfig(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(400)
+		w.Write([]byte(`{"error":{"message":"Bad request"}}`))
+	}))
+	defer server.Close()
+
+	type retryEvent struct {
+		attempt int
+		delay   time.Duration
+	}
+	var events []retryEvent
+
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetry(0, 0),
+		WithRetryConfig(&RetryConfig{
+			MaxRetries:   2,
+			InitialDelay: time.Millisecond,
+			RetryableError: func(err error) bool {
+				// Retry everything, including client errors
+				return true
+			},
+			OnRetry: func(attempt int, err error, delay time.Duration) {
+				if _, ok := IsRequestError(err); !ok {
+					t.Errorf("expected RequestError in OnRetry, got %v", err)
+				}
+				events = append(events, retryEvent{attempt, delay})
+			},
+		}),
+	)
+
+	err := client.doRequest(context.Background(), "GET", "/test", nil, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if len(events) != 2 || events[0].attempt != 1 || events[1].attempt != 2 {
+		t.Errorf("unexpected OnRetry events: %+v", events)
+	}
+	if len(events) == 2 && events[1].delay != 2*time.Millisecond {
+		t.Errorf("expected default multiplier to apply, got delay %v", events[1].delay)
+	}
+}
+
+func TestRetryConfigDefaultsInitialDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		w.Write([]byte("Server Error"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetry(3, 5*time.Millisecond),
+	)
+
+	var delays []time.Duration
+	_, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("Hello")},
+		WithModel("test-model"),
+		WithRetryOverride(&RetryConfig{
+			MaxRetries: 1,
+			OnRetry: func(attempt int, err error, delay time.Duration) {
+				delays = append(delays, delay)
+			},
+		}),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(delays) != 1 || delays[0] != 5*time.Millisecond {
+		t.Errorf("expected the client's retry delay, got %v", delays)
+	}
+}
+
+func TestPerRequestRetryOverride(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(500)
+		w.Write([]byte("Server Error"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetry(3, time.Millisecond),
+	)
+
+	messages := []Message{CreateUserMessage("Hello")}
+
+	_, err := client.ChatComplete(context.Background(), messages,
+		WithModel("test-model"),
+		WithRetryOverride(&RetryConfig{MaxRetries: 0}),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected retries to be disabled, got %d attempts", attempts)
+	}
+
+	attempts = 0
+	_, err = client.Complete(context.Background(), "Hello",
+		WithCompletionModel("test-model"),
+		WithCompletionRetryOverride(&RetryConfig{MaxRetries: 1, InitialDelay: time.Millisecond}),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	ctx := ContextWithRetryConfig(context.Background(), &RetryConfig{MaxRetries: 0})
+	if _, err := client.GetCredits(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected context override to disable retries, got %d attempts", attempts)
+	}
+
+	attempts = 0
+	if _, err := client.GetCredits(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts != 4 {
+		t.Errorf("expected client retry settings without override, got %d attempts", attempts)
+	}
+}
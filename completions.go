@@ -23,6 +23,9 @@ func (c *Client) Complete(ctx context.Context, prompt string, opts ...Completion
 	for _, opt := range opts {
 		opt(req)
 	}
+	if req.optionErr != nil {
+		return nil, req.optionErr
+	}
 
 	// Handle model suffixes
 	req.Model = processModelSuffix(req.Model, req)
@@ -32,6 +35,11 @@ func (c *Client) Complete(ctx context.Context, prompt string, opts ...Completion
 		return nil, ErrNoModel
 	}
 
+	// Apply per-request retry configuration
+	if req.retryConfig != nil {
+		ctx = ContextWithRetryConfig(ctx, req.retryConfig)
+	}
+
 	// Make request
 	var resp CompletionResponse
 	err := c.doRequest(ctx, "POST", "/completions", req, &resp)
@@ -39,6 +47,11 @@ func (c *Client) Complete(ctx context.Context, prompt string, opts ...Completion
 		return nil, err
 	}
 
+	// Fetch generation stats if requested; a failure does not fail the request
+	if req.generationStats {
+		resp.Generation, resp.GenerationErr = c.attachGenerationStats(ctx, resp.ID)
+	}
+
 	return &resp, nil
 }
 
@@ -61,6 +74,9 @@ func (c *Client) CompleteStream(ctx context.Context, prompt string, opts ...Comp
 	for _, opt := range opts {
 		opt(req)
 	}
+	if req.optionErr != nil {
+		return nil, req.optionErr
+	}
 
 	// Handle model suffixes
 	req.Model = processModelSuffix(req.Model, req)
@@ -442,3 +442,34 @@ func TestCompleteStream(t *testing.T) {
 		t.Error("expected to receive at least one event")
 	}
 }
+
+func TestCompleteUsageAccounting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		if req.Usage == nil || !req.Usage.Include {
+			t.Error("expected usage.include to be true")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"cmpl-1","choices":[{"text":"Hi"}],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6,"cost":0.001}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.Complete(context.Background(), "Hello",
+		WithCompletionModel("openai/gpt-3.5-turbo-instruct"),
+		WithCompletionUsageAccounting(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Usage.Cost != 0.001 {
+		t.Errorf("expected cost 0.001, got %v", resp.Usage.Cost)
+	}
+}
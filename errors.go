@@ -1,8 +1,11 @@
 package openrouter
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"time"
 )
 
 // RequestError represents an error returned by the OpenRouter API.
@@ -11,6 +14,32 @@ type RequestError struct {
 	Message    string
 	Type       string
 	Code       string
+	// Headers contains the HTTP response headers of the failed request
+	Headers http.Header
+	// RateLimit contains the rate limit state parsed from the response headers, if present
+	RateLimit *RateLimitInfo
+	// Metadata contains the error metadata, such as provider_name and raw for provider errors
+	Metadata map[string]interface{}
+}
+
+// newRequestError builds a RequestError from an error response and its body.
+func newRequestError(resp *http.Response, body []byte) *RequestError {
+	reqErr := &RequestError{
+		StatusCode: resp.StatusCode,
+		Message:    string(body),
+		Headers:    resp.Header,
+		RateLimit:  ParseRateLimitInfo(resp.Header),
+	}
+
+	var errorResp ErrorResponse
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		reqErr.Message = errorResp.Error.Message
+		reqErr.Type = errorResp.Error.Type
+		reqErr.Code = errorResp.Error.Code
+		reqErr.Metadata = errorResp.Error.Metadata
+	}
+
+	return reqErr
 }
 
 // Error implements the error interface.
@@ -26,6 +55,16 @@ func (e *RequestError) IsRateLimitError() bool {
 	return e.StatusCode == 429
 }
 
+// RetryAfter returns the delay requested by the server's Retry-After header.
+// Both the delay-seconds and HTTP-date forms are supported. The second return
+// value is false if the header is absent or invalid.
+func (e *RequestError) RetryAfter() (time.Duration, bool) {
+	if e.Headers == nil {
+		return 0, false
+	}
+	return parseRetryAfter(e.Headers.Get("Retry-After"))
+}
+
 // IsAuthenticationError returns true if the error is an authentication error.
 func (e *RequestError) IsAuthenticationError() bool {
 	return e.StatusCode == 401
@@ -46,6 +85,13 @@ func (e *RequestError) IsServerError() bool {
 	return e.StatusCode >= 500
 }
 
+// ProviderName returns the name of the provider that failed the request, from the
+// error metadata. It is empty for errors not caused by a provider.
+func (e *RequestError) ProviderName() string {
+	name, _ := e.Metadata["provider_name"].(string)
+	return name
+}
+
 // StreamError represents an error that occurs during streaming.
 type StreamError struct {
 	Err     error
@@ -79,6 +125,85 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation error: %s", e.Message)
 }
 
+// StructuredOutputError is returned when a model response cannot be decoded into
+// the requested type or does not match the expected JSON schema.
+type StructuredOutputError struct {
+	// Content is the raw message content returned by the model
+	Content string
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *StructuredOutputError) Error() string {
+	return fmt.Sprintf("openrouter: invalid structured output: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *StructuredOutputError) Unwrap() error {
+	return e.Err
+}
+
+// FallbackError is returned by a FallbackClient when every step of the chain failed.
+type FallbackError struct {
+	// Attempts contains every failed attempt, in order
+	Attempts []FallbackAttempt
+}
+
+// Error implements the error interface.
+func (e *FallbackError) Error() string {
+	if err := e.Unwrap(); err != nil {
+		return fmt.Sprintf("openrouter: all %d fallback attempts failed: %v", len(e.Attempts), err)
+	}
+	return fmt.Sprintf("openrouter: all %d fallback attempts failed", len(e.Attempts))
+}
+
+// Unwrap returns the error of the last attempt, if it failed with an error.
+func (e *FallbackError) Unwrap() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// CircuitOpenError is returned when a circuit breaker fails a request fast because
+// the model or provider keeps failing. It matches ErrCircuitOpen with errors.Is.
+type CircuitOpenError struct {
+	// Model is the requested model
+	Model string
+	// Provider is the open provider the request was restricted to; empty when the model's circuit is open
+	Provider string
+	// RetryAfter is how long until the circuit lets a trial request through; zero when it is half-open
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	if e.Provider != "" {
+		return fmt.Sprintf("openrouter: circuit open for provider %s (model %s)", e.Provider, e.Model)
+	}
+	return fmt.Sprintf("openrouter: circuit open for model %s", e.Model)
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// ErrCircuitOpen is matched by every *CircuitOpenError.
+var ErrCircuitOpen = errors.New("openrouter: circuit open")
+
+// ErrStreamFirstTokenTimeout is the cause of the StreamError returned when a stream
+// produces no event within StreamTimeouts.FirstToken.
+var ErrStreamFirstTokenTimeout = errors.New("openrouter: stream first token timeout")
+
+// ErrStreamIdleTimeout is the cause of the StreamError returned when a stream receives
+// no data for StreamTimeouts.Idle.
+var ErrStreamIdleTimeout = errors.New("openrouter: stream idle timeout")
+
+// ErrStreamTotalTimeout is the cause of the StreamError returned when a stream runs
+// longer than StreamTimeouts.Total.
+var ErrStreamTotalTimeout = errors.New("openrouter: stream total timeout")
+
 // ErrNoAPIKey is returned when no API key is provided.
 var ErrNoAPIKey = &ValidationError{Field: "apiKey", Message: "API key is required"}
 
@@ -91,6 +216,9 @@ var ErrNoMessages = &ValidationError{Field: "messages", Message: "at least one m
 // ErrNoPrompt is returned when no prompt is provided for completion.
 var ErrNoPrompt = &ValidationError{Field: "prompt", Message: "prompt is required"}
 
+// ErrMaxIterationsReached is returned when a ToolRunner stops before the model produced a final answer.
+var ErrMaxIterationsReached = errors.New("openrouter: tool runner reached max iterations")
+
 // IsRequestError checks if an error is a RequestError and returns it.
 func IsRequestError(err error) (*RequestError, bool) {
 	var reqErr *RequestError
@@ -105,6 +233,27 @@ func IsStreamError(err error) (*StreamError, bool) {
 	return streamErr, ok
 }
 
+// IsStructuredOutputError checks if an error is a StructuredOutputError and returns it.
+func IsStructuredOutputError(err error) (*StructuredOutputError, bool) {
+	var outputErr *StructuredOutputError
+	ok := errors.As(err, &outputErr)
+	return outputErr, ok
+}
+
+// IsFallbackError checks if an error is a FallbackError and returns it.
+func IsFallbackError(err error) (*FallbackError, bool) {
+	var fallbackErr *FallbackError
+	ok := errors.As(err, &fallbackErr)
+	return fallbackErr, ok
+}
+
+// IsCircuitOpenError checks if an error is a CircuitOpenError and returns it.
+func IsCircuitOpenError(err error) (*CircuitOpenError, bool) {
+	var circuitErr *CircuitOpenError
+	ok := errors.As(err, &circuitErr)
+	return circuitErr, ok
+}
+
 // IsValidationError checks if an error is a ValidationError and returns it.
 func IsValidationError(err error) (*ValidationError, bool) {
 	var valErr *ValidationError
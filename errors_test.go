@@ -2,6 +2,7 @@ package openrouter
 
 import (
 	"errors"
+	"net/http"
 	"strings"
 	"testing"
 )
@@ -292,3 +293,85 @@ func TestRequestErrorStatusChecks(t *testing.T) {
 		}
 	}
 }
+
+func TestRequestErrorProviderName(t *testing.T) {
+	resp := &http.Response{StatusCode: 502, Header: http.Header{}}
+	body := []byte(`{"error":{"message":"Provider returned error","code":"502","metadata":{"provider_name":"Together","raw":"upstream"}}}`)
+
+	reqErr := newRequestError(resp, body)
+	if got := reqErr.ProviderName(); got != "Together" {
+		t.Errorf("expected provider name Together, got %q", got)
+	}
+	if reqErr.Metadata["raw"] != "upstream" {
+		t.Errorf("expected raw metadata, got %v", reqErr.Metadata)
+	}
+
+	if got := (&RequestError{StatusCode: 500}).ProviderName(); got != "" {
+		t.Errorf("expected no provider name, got %q", got)
+	}
+}
+
+func TestParseStreamErrorEvent(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantErr    bool
+		statusCode int
+		code       string
+		provider   string
+	}{
+		{
+			name: "content chunk",
+			data: `{"choices":[{"index":0,"delta":{"content":"the word \"error\""}}]}`,
+		},
+		{
+			name:       "numeric code",
+			data:       `{"error":{"code":502,"message":"Bad gateway","metadata":{"provider_name":"Fireworks"}},"choices":[{"index":0,"finish_reason":"error"}]}`,
+			wantErr:    true,
+			statusCode: 502,
+			code:       "502",
+			provider:   "Fireworks",
+		},
+		{
+			name:     "string code with chunk provider",
+			data:     `{"provider":"Together","error":{"code":"server_error","message":"boom"}}`,
+			wantErr:  true,
+			code:     "server_error",
+			provider: "Together",
+		},
+		{
+			name:    "error finish reason without details",
+			data:    `{"choices":[{"index":0,"delta":{},"finish_reason":"error"}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseStreamErrorEvent([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStreamErrorEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if _, ok := IsStreamError(err); !ok {
+				t.Errorf("expected a StreamError, got %T", err)
+			}
+
+			reqErr, ok := IsRequestError(err)
+			if tt.code == "" {
+				if ok {
+					t.Errorf("expected no request error, got %v", reqErr)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected a request error, got %v", err)
+			}
+			if reqErr.StatusCode != tt.statusCode || reqErr.Code != tt.code || reqErr.ProviderName() != tt.provider {
+				t.Errorf("unexpected request error: %+v", reqErr)
+			}
+		})
+	}
+}
@@ -100,6 +100,10 @@ func main() {
 	// Example 4: Forced tool choice
 	fmt.Println("\n=== Example 4: Forced Tool Choice ===")
 	runForcedToolChoiceExample(ctx, client)
+
+	// Example 5: Automatic tool loop with ToolRunner
+	fmt.Println("\n=== Example 5: Automatic Tool Loop with ToolRunner ===")
+	runToolRunnerExample(ctx, client)
 }
 
 func runBookSearchExample(ctx context.Context, client *openrouter.Client) {
@@ -461,3 +465,70 @@ func runForcedToolChoiceExample(ctx context.Context, client *openrouter.Client)
 		fmt.Printf("Assistant: %s\n", resp.Choices[0].Message.Content)
 	}
 }
+
+func runToolRunnerExample(ctx context.Context, client *openrouter.Client) {
+	weatherTool := openrouter.Tool{
+		Type: "function",
+		Function: openrouter.Function{
+			Name:        "get_current_weather",
+			Description: "Get the current weather for a specific location",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City name",
+					},
+					"unit": map[string]interface{}{
+						"type": "string",
+						"enum": []string{"celsius", "fahrenheit"},
+					},
+				},
+				"required": []string{"location"},
+			},
+		},
+	}
+
+	// The runner executes tool calls and sends the results back automatically
+	runner := openrouter.NewToolRunner(client,
+		openrouter.WithToolHandler(weatherTool, func(ctx context.Context, arguments string) (string, error) {
+			var args struct {
+				Location string `json:"location"`
+				Unit     string `json:"unit"`
+			}
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", err
+			}
+			if args.Unit == "" {
+				args.Unit = "celsius"
+			}
+			return getCurrentWeather(args.Location, args.Unit)
+		}),
+		openrouter.WithMaxIterations(5),
+		openrouter.WithToolRunnerHooks(openrouter.ToolRunnerHooks{
+			OnToolCall: func(call openrouter.ToolCall) {
+				fmt.Printf("Running tool %s with %s\n", call.Function.Name, call.Function.Arguments)
+			},
+		}),
+	)
+
+	messages := []openrouter.Message{
+		openrouter.CreateUserMessage("Compare the weather in Paris and Berlin."),
+	}
+
+	parallel := true
+	result, err := runner.Run(ctx, messages,
+		openrouter.WithModel("openai/gpt-4o-mini"),
+		openrouter.WithParallelToolCalls(&parallel),
+		openrouter.WithMaxTokens(500),
+	)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+
+	fmt.Printf("Completed in %d iterations\n", result.Iterations)
+	if len(result.FinalResponse.Choices) > 0 {
+		fmt.Printf("Assistant: %s\n", result.FinalResponse.Choices[0].Message.Content)
+	}
+}
@@ -0,0 +1,314 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// FallbackPolicy decides whether the outcome of a step moves a FallbackClient on to
+// the next step. It receives the response, which may be nil, and the error of the attempt.
+type FallbackPolicy func(resp *ChatCompletionResponse, err error) bool
+
+// FallbackStep is one step of a fallback chain.
+type FallbackStep struct {
+	// Model is the model to request; empty keeps the model set by the request options
+	Model string
+	// Provider optionally sets the provider routing for this step
+	Provider *Provider
+	// Options are applied after the request options, for settings specific to this step
+	Options []ChatCompletionOption
+	// Timeout limits each attempt of this step, so that stalled requests and streams fall back
+	Timeout time.Duration
+	// ShouldFallback overrides the client's fallback policy for this step
+	ShouldFallback FallbackPolicy
+}
+
+// FallbackAttempt records a failed step of a fallback chain.
+type FallbackAttempt struct {
+	// Step is the index of the step in the chain
+	Step int
+	// Model is the model requested by the step
+	Model string
+	// Response is the response that was rejected, if one was received
+	Response *ChatCompletionResponse
+	// Err is the error of the attempt; nil if the response was rejected by the policy
+	Err error
+	// Duration is how long the attempt took
+	Duration time.Duration
+}
+
+// FallbackResult is the outcome of a fallback chain.
+type FallbackResult struct {
+	// Response is the accepted response
+	Response *ChatCompletionResponse
+	// Model is the model that answered
+	Model string
+	// Step is the index of the step that answered
+	Step int
+	// Attempts contains the failed attempts before the answer, in order
+	Attempts []FallbackAttempt
+}
+
+// FallbackHooks contains optional callbacks invoked as a fallback chain runs.
+type FallbackHooks struct {
+	// OnFallback is called after a step fails, before the next step is tried.
+	OnFallback func(attempt FallbackAttempt)
+	// OnChunk is called for each chunk received by ChatCompleteStream.
+	OnChunk func(step int, chunk ChatCompletionResponse)
+}
+
+// FallbackClient tries an ordered list of models and provider configurations until
+// one produces an acceptable answer. Unlike WithModels, which falls back on the
+// server, it also falls back on failures only the client observes, such as stalled
+// streams, invalid structured output or content-filtered responses.
+//
+// A FallbackClient is safe for concurrent use once configured.
+type FallbackClient struct {
+	client *Client
+	steps  []FallbackStep
+	policy FallbackPolicy
+	hooks  FallbackHooks
+}
+
+// FallbackOption is a functional option for configuring a FallbackClient.
+type FallbackOption func(*FallbackClient)
+
+// WithFallbackSteps appends steps to the fallback chain.
+func WithFallbackSteps(steps ...FallbackStep) FallbackOption {
+	return func(f *FallbackClient) {
+		f.steps = append(f.steps, steps...)
+	}
+}
+
+// WithFallbackPolicy sets the policy used by steps without their own. Defaults to
+// DefaultFallbackPolicy.
+func WithFallbackPolicy(policy FallbackPolicy) FallbackOption {
+	return func(f *FallbackClient) {
+		f.policy = policy
+	}
+}
+
+// WithFallbackHooks sets callbacks that observe the fallback chain.
+func WithFallbackHooks(hooks FallbackHooks) FallbackOption {
+	return func(f *FallbackClient) {
+		f.hooks = hooks
+	}
+}
+
+// NewFallbackClient creates a FallbackClient that sends requests through the given client.
+//
+// Example:
+//
+//	fallback := openrouter.NewFallbackClient(client,
+//	    openrouter.WithFallbackSteps(
+//	        openrouter.FallbackStep{Model: "anthropic/claude-sonnet-4", Timeout: 30 * time.Second},
+//	        openrouter.FallbackStep{
+//	            Model:    "openai/gpt-4o",
+//	            Provider: &openrouter.Provider{Order: []string{"azure"}},
+//	        },
+//	    ),
+//	    openrouter.WithFallbackPolicy(openrouter.FallbackOnAny(
+//	        openrouter.DefaultFallbackPolicy,
+//	        openrouter.FallbackOnInvalidJSON,
+//	    )),
+//	)
+//
+//	result, err := fallback.ChatComplete(ctx, messages, openrouter.WithJSONMode())
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%s answered after %d failed attempts\n", result.Model, len(result.Attempts))
+func NewFallbackClient(client *Client, opts ...FallbackOption) *FallbackClient {
+	f := &FallbackClient{
+		client: client,
+		policy: DefaultFallbackPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// ChatComplete sends the request to each step in turn until the step's policy
+// accepts the outcome. If every step fails, the error is a *FallbackError and the
+// result contains the attempts and the last response received, if any. An error the
+// policy does not fall back on ends the chain and is returned as is.
+func (f *FallbackClient) ChatComplete(ctx context.Context, messages []Message, opts ...ChatCompletionOption) (*FallbackResult, error) {
+	return f.run(ctx, func(ctx context.Context, step int, opts []ChatCompletionOption) (*ChatCompletionResponse, error) {
+		return f.client.ChatComplete(ctx, messages, opts...)
+	}, opts)
+}
+
+// ChatCompleteStream streams the request from each step in turn and accumulates the
+// chunks into a response. Chunks are passed to FallbackHooks.OnChunk as they arrive;
+// when a step fails mid-stream, the next step starts over from the beginning.
+func (f *FallbackClient) ChatCompleteStream(ctx context.Context, messages []Message, opts ...ChatCompletionOption) (*FallbackResult, error) {
+	return f.run(ctx, func(ctx context.Context, step int, opts []ChatCompletionOption) (*ChatCompletionResponse, error) {
+		stream, err := f.client.ChatCompleteStream(ctx, messages, opts...)
+		if err != nil {
+			return nil, err
+		}
+		defer stream.Close()
+
+		acc := NewChatStreamAccumulator()
+		for chunk := range stream.Events() {
+			acc.Add(chunk)
+			if f.hooks.OnChunk != nil {
+				f.hooks.OnChunk(step, chunk)
+			}
+		}
+
+		return acc.Response(), stream.Err()
+	}, opts)
+}
+
+// attemptFunc performs a single attempt of a step with the combined options.
+type attemptFunc func(ctx context.Context, step int, opts []ChatCompletionOption) (*ChatCompletionResponse, error)
+
+// run walks the fallback chain.
+func (f *FallbackClient) run(ctx context.Context, attempt attemptFunc, opts []ChatCompletionOption) (*FallbackResult, error) {
+	if len(f.steps) == 0 {
+		return nil, &ValidationError{Field: "steps", Message: "fallback chain has no steps"}
+	}
+
+	result := &FallbackResult{}
+
+	for i, step := range f.steps {
+		stepCtx, cancel := ctx, context.CancelFunc(func() {})
+		if step.Timeout > 0 {
+			stepCtx, cancel = context.WithTimeout(ctx, step.Timeout)
+		}
+
+		start := time.Now()
+		resp, err := attempt(stepCtx, i, step.options(opts))
+		cancel()
+
+		// The caller gave up; later steps would fail the same way
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		policy := step.ShouldFallback
+		if policy == nil {
+			policy = f.policy
+		}
+
+		failed := FallbackAttempt{
+			Step:     i,
+			Model:    step.Model,
+			Response: resp,
+			Err:      err,
+			Duration: time.Since(start),
+		}
+		result.Response = resp
+
+		if !policy(resp, err) {
+			// Errors the policy does not fall back on end the chain
+			if err != nil {
+				result.Attempts = append(result.Attempts, failed)
+				return result, err
+			}
+
+			result.Step = i
+			result.Model = step.Model
+			if result.Model == "" && resp != nil {
+				result.Model = resp.Model
+			}
+			return result, nil
+		}
+
+		result.Attempts = append(result.Attempts, failed)
+
+		if f.hooks.OnFallback != nil && i < len(f.steps)-1 {
+			f.hooks.OnFallback(failed)
+		}
+	}
+
+	return result, &FallbackError{Attempts: result.Attempts}
+}
+
+// options returns the request options for the step.
+func (s FallbackStep) options(base []ChatCompletionOption) []ChatCompletionOption {
+	opts := append([]ChatCompletionOption(nil), base...)
+	if s.Model != "" {
+		opts = append(opts, WithModel(s.Model))
+	}
+	if s.Provider != nil {
+		opts = append(opts, WithProvider(*s.Provider))
+	}
+	return append(opts, s.Options...)
+}
+
+// DefaultFallbackPolicy falls back on errors that another model or provider may not
+// hit, and on responses without an answer: responses without choices and responses
+// that finished with "content_filter" or "error". It does not fall back on requests
+// rejected before sending (*ValidationError), nor on authentication, permission or
+// payment errors, which fail on every step. API 400 errors do fall back, since they
+// are often specific to one model, such as exceeding its context length or using a
+// parameter it does not support.
+func DefaultFallbackPolicy(resp *ChatCompletionResponse, err error) bool {
+	if err != nil {
+		if _, ok := IsValidationError(err); ok {
+			return false
+		}
+		if reqErr, ok := IsRequestError(err); ok {
+			switch reqErr.StatusCode {
+			case 401, 402, 403:
+				return false
+			}
+		}
+		return true
+	}
+
+	return FallbackOnFinishReason("content_filter", "error")(resp, nil)
+}
+
+// FallbackOnError falls back on any error.
+func FallbackOnError(resp *ChatCompletionResponse, err error) bool {
+	return err != nil
+}
+
+// FallbackOnInvalidJSON falls back when the response content is not valid JSON, for
+// use with structured outputs and JSON mode. It does not fall back on errors; combine
+// it with DefaultFallbackPolicy using FallbackOnAny.
+func FallbackOnInvalidJSON(resp *ChatCompletionResponse, err error) bool {
+	if err != nil {
+		return false
+	}
+	if resp == nil || len(resp.Choices) == 0 {
+		return true
+	}
+
+	content, _ := resp.Choices[0].Message.Content.(string)
+	return !json.Valid([]byte(content))
+}
+
+// FallbackOnFinishReason returns a policy that falls back when the response has no
+// choices or its first choice finished with one of the given reasons.
+func FallbackOnFinishReason(reasons ...string) FallbackPolicy {
+	return func(resp *ChatCompletionResponse, err error) bool {
+		if err != nil {
+			return false
+		}
+		if resp == nil || len(resp.Choices) == 0 {
+			return true
+		}
+		return slices.Contains(reasons, resp.Choices[0].FinishReason)
+	}
+}
+
+// FallbackOnAny returns a policy that falls back when any of the given policies does.
+func FallbackOnAny(policies ...FallbackPolicy) FallbackPolicy {
+	return func(resp *ChatCompletionResponse, err error) bool {
+		for _, policy := range policies {
+			if policy(resp, err) {
+				return true
+			}
+		}
+		return false
+	}
+}
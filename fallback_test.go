@@ -0,0 +1,220 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFallbackClientChatComplete(t *testing.T) {
+	var mu sync.Mutex
+	var models []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		mu.Lock()
+		models = append(models, req.Model)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Model {
+		case "model/a":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"message":"overloaded"}}`))
+		case "model/b":
+			if req.Provider == nil || len(req.Provider.Order) != 1 || req.Provider.Order[0] != "azure" {
+				t.Errorf("expected step provider to be sent, got %+v", req.Provider)
+			}
+			json.NewEncoder(w).Encode(ChatCompletionResponse{
+				Model:   "model/b",
+				Choices: []Choice{{Message: Message{Role: "assistant", Content: ""}, FinishReason: "content_filter"}},
+			})
+		default:
+			if req.Temperature == nil || *req.Temperature != 0.2 {
+				t.Errorf("expected base options to be kept, got %v", req.Temperature)
+			}
+			resp := finalResponse("answer")
+			resp.Model = req.Model
+			json.NewEncoder(w).Encode(resp)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithRetry(0, time.Millisecond))
+
+	var fallbacks []FallbackAttempt
+	fallback := NewFallbackClient(client,
+		WithFallbackSteps(
+			FallbackStep{Model: "model/a"},
+			FallbackStep{Model: "model/b", Provider: &Provider{Order: []string{"azure"}}},
+			FallbackStep{Model: "model/c"},
+		),
+		WithFallbackHooks(FallbackHooks{
+			OnFallback: func(attempt FallbackAttempt) {
+				fallbacks = append(fallbacks, attempt)
+			},
+		}),
+	)
+
+	result, err := fallback.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithTemperature(0.2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Model != "model/c" || result.Step != 2 {
+		t.Errorf("expected model/c at step 2 to answer, got %s at step %d", result.Model, result.Step)
+	}
+	if result.Response.Choices[0].Message.Content != "answer" {
+		t.Errorf("unexpected response: %+v", result.Response)
+	}
+	if len(result.Attempts) != 2 || len(fallbacks) != 2 {
+		t.Fatalf("expected 2 failed attempts, got %d (%d hooks)", len(result.Attempts), len(fallbacks))
+	}
+
+	if reqErr, ok := IsRequestError(result.Attempts[0].Err); !ok || reqErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected first attempt to fail with 503, got %v", result.Attempts[0].Err)
+	}
+	second := result.Attempts[1]
+	if second.Model != "model/b" || second.Err != nil || second.Response == nil || second.Response.Choices[0].FinishReason != "content_filter" {
+		t.Errorf("expected second attempt to be rejected by finish reason, got %+v", second)
+	}
+	if len(models) != 3 {
+		t.Errorf("expected 3 requests, got %v", models)
+	}
+}
+
+func TestFallbackClientStopsOnFatalErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid key"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithRetry(0, time.Millisecond))
+	fallback := NewFallbackClient(client, WithFallbackSteps(FallbackStep{Model: "model/a"}, FallbackStep{Model: "model/b"}))
+
+	result, err := fallback.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")})
+	if reqErr, ok := IsRequestError(err); !ok || !reqErr.IsAuthenticationError() {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+	if requests != 1 || len(result.Attempts) != 1 {
+		t.Errorf("expected the chain to stop after 1 request, got %d requests", requests)
+	}
+}
+
+func TestDefaultFallbackPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		fallback bool
+	}{
+		{"context length", &RequestError{StatusCode: 400, Message: "maximum context length exceeded"}, true},
+		{"server error", &RequestError{StatusCode: 502}, true},
+		{"rate limit", &RequestError{StatusCode: 429}, true},
+		{"network error", errors.New("connection reset"), true},
+		{"validation", &ValidationError{Field: "messages", Message: "required"}, false},
+		{"authentication", &RequestError{StatusCode: 401}, false},
+		{"payment", &RequestError{StatusCode: 402}, false},
+		{"permission", &RequestError{StatusCode: 403}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultFallbackPolicy(nil, tt.err); got != tt.fallback {
+				t.Errorf("expected fallback %v, got %v", tt.fallback, got)
+			}
+		})
+	}
+}
+
+func TestFallbackClientAllFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse("not json"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	fallback := NewFallbackClient(client,
+		WithFallbackSteps(FallbackStep{Model: "model/a"}, FallbackStep{Model: "model/b"}),
+		WithFallbackPolicy(FallbackOnAny(DefaultFallbackPolicy, FallbackOnInvalidJSON)),
+	)
+
+	result, err := fallback.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")})
+	fallbackErr, ok := IsFallbackError(err)
+	if !ok {
+		t.Fatalf("expected FallbackError, got %v", err)
+	}
+	if len(fallbackErr.Attempts) != 2 || fallbackErr.Unwrap() != nil {
+		t.Errorf("expected 2 rejected attempts without errors, got %+v", fallbackErr.Attempts)
+	}
+	if result.Response == nil {
+		t.Error("expected the last response to be returned")
+	}
+
+	if _, err := NewFallbackClient(client).ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}); err == nil {
+		t.Error("expected error for empty chain")
+	}
+}
+
+func TestFallbackClientStreamStall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"id":"s","choices":[{"index":0,"delta":{"role":"assistant","content":"partial"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+
+		if req.Model == "model/slow" {
+			// Stall until the client gives up
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`data: {"id":"s","choices":[{"index":0,"delta":{"content":" done"},"finish_reason":"stop"}]}` + "\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	var chunks int
+	fallback := NewFallbackClient(client,
+		WithFallbackSteps(
+			FallbackStep{Model: "model/slow", Timeout: 100 * time.Millisecond},
+			FallbackStep{Model: "model/fast"},
+		),
+		WithFallbackHooks(FallbackHooks{
+			OnChunk: func(step int, chunk ChatCompletionResponse) { chunks++ },
+		}),
+	)
+
+	result, err := fallback.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Model != "model/fast" {
+		t.Errorf("expected model/fast to answer, got %s", result.Model)
+	}
+	if content := result.Response.Choices[0].Message.Content; content != "partial done" {
+		t.Errorf("expected accumulated content, got %q", content)
+	}
+	if len(result.Attempts) != 1 || !errors.Is(result.Attempts[0].Err, context.DeadlineExceeded) {
+		t.Errorf("expected the stalled attempt to time out, got %+v", result.Attempts)
+	}
+	if chunks != 3 {
+		t.Errorf("expected 3 chunks across both steps, got %d", chunks)
+	}
+}
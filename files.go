@@ -0,0 +1,122 @@
+package openrouter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// PDFEngine represents the engine the file-parser plugin uses to parse PDFs.
+type PDFEngine string
+
+const (
+	// PDFEnginePDFText extracts embedded text; free, best for well-structured PDFs
+	PDFEnginePDFText PDFEngine = "pdf-text"
+	// PDFEngineMistralOCR runs OCR; best for scanned documents and PDFs with images
+	PDFEngineMistralOCR PDFEngine = "mistral-ocr"
+	// PDFEngineNative passes the file to models with native file support
+	PDFEngineNative PDFEngine = "native"
+)
+
+// NewFileParserPlugin creates a file-parser plugin configuration that parses PDFs
+// with the given engine. Without the plugin, OpenRouter uses the model's native
+// file support if available and falls back to mistral-ocr.
+func NewFileParserPlugin(engine PDFEngine) Plugin {
+	return Plugin{
+		ID:  "file-parser",
+		PDF: &PDFParserConfig{Engine: string(engine)},
+	}
+}
+
+// NewFilePart creates a file content part from the file contents. The data is sent
+// as a base64 data URL; its MIME type is derived from the filename's extension, or
+// detected from the contents if the extension is unknown.
+func NewFilePart(filename string, data []byte) ContentPart {
+	return ContentPart{
+		Type: "file",
+		File: &File{
+			Filename: filename,
+			FileData: dataURL(detectMIMEType(filename, data), data),
+		},
+	}
+}
+
+// NewFilePartFromReader creates a file content part by reading r to the end.
+func NewFilePartFromReader(filename string, r io.Reader) (ContentPart, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ContentPart{}, fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+
+	return NewFilePart(filename, data), nil
+}
+
+// NewFilePartFromPath creates a file content part from the file at path. The
+// part's filename is the base name of path.
+func NewFilePartFromPath(path string) (ContentPart, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ContentPart{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return NewFilePart(filepath.Base(path), data), nil
+}
+
+// NewFilePartFromURL creates a file content part that references a publicly
+// accessible file instead of embedding it.
+func NewFilePartFromURL(filename string, url string) ContentPart {
+	return ContentPart{
+		Type: "file",
+		File: &File{
+			Filename: filename,
+			FileData: url,
+		},
+	}
+}
+
+// CreateFileMessage creates a message with text followed by file content parts.
+//
+// Example:
+//
+//	part, err := openrouter.NewFilePartFromPath("contract.pdf")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	resp, err := client.ChatComplete(ctx,
+//	    []openrouter.Message{openrouter.CreateFileMessage("user", "Summarize this contract.", part)},
+//	    openrouter.WithModel("anthropic/claude-sonnet-4"),
+//	    openrouter.WithPlugins(openrouter.NewFileParserPlugin(openrouter.PDFEnginePDFText)),
+//	)
+func CreateFileMessage(role string, text string, files ...ContentPart) Message {
+	parts := make([]ContentPart, 0, len(files)+1)
+	parts = append(parts, ContentPart{Type: "text", Text: text})
+	parts = append(parts, files...)
+
+	return Message{
+		Role:    role,
+		Content: parts,
+	}
+}
+
+// detectMIMEType returns the MIME type of a file without parameters, based on the
+// filename's extension or, failing that, on the contents.
+func detectMIMEType(filename string, data []byte) string {
+	mimeType := mime.TypeByExtension(filepath.Ext(filename))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return mediaType
+	}
+	return mimeType
+}
+
+// dataURL encodes data as a base64 data URL.
+func dataURL(mimeType string, data []byte) string {
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,125 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testPDF = []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+
+func TestNewFilePart(t *testing.T) {
+	part := NewFilePart("report.pdf", testPDF)
+	if part.Type != "file" || part.File == nil {
+		t.Fatalf("unexpected file part: %+v", part)
+	}
+	if part.File.Filename != "report.pdf" {
+		t.Errorf("expected filename 'report.pdf', got %q", part.File.Filename)
+	}
+
+	prefix := "data:application/pdf;base64,"
+	if !strings.HasPrefix(part.File.FileData, prefix) {
+		t.Fatalf("expected PDF data URL, got %q", part.File.FileData)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(part.File.FileData, prefix))
+	if err != nil || string(decoded) != string(testPDF) {
+		t.Errorf("expected data URL to contain the file, got %q (%v)", decoded, err)
+	}
+
+	// Unknown extensions fall back to content sniffing, without parameters
+	part = NewFilePart("notes", []byte("plain text notes"))
+	if !strings.HasPrefix(part.File.FileData, "data:text/plain;base64,") {
+		t.Errorf("expected sniffed text/plain data URL, got %q", part.File.FileData)
+	}
+
+	part = NewFilePartFromURL("paper.pdf", "https://example.com/paper.pdf")
+	if part.File.FileData != "https://example.com/paper.pdf" {
+		t.Errorf("expected URL to be passed through, got %q", part.File.FileData)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("disk error") }
+
+func TestNewFilePartFromReaderAndPath(t *testing.T) {
+	part, err := NewFilePartFromReader("report.pdf", strings.NewReader(string(testPDF)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if part.File.FileData != NewFilePart("report.pdf", testPDF).File.FileData {
+		t.Error("expected reader part to match the bytes part")
+	}
+
+	if _, err := NewFilePartFromReader("report.pdf", failingReader{}); err == nil {
+		t.Error("expected read error")
+	}
+
+	path := filepath.Join(t.TempDir(), "invoice.pdf")
+	if err := os.WriteFile(path, testPDF, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	part, err = NewFilePartFromPath(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if part.File.Filename != "invoice.pdf" {
+		t.Errorf("expected base name as filename, got %q", part.File.Filename)
+	}
+
+	if _, err := NewFilePartFromPath(filepath.Join(t.TempDir(), "missing.pdf")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestChatCompleteWithFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		plugins := req["plugins"].([]interface{})
+		plugin := plugins[0].(map[string]interface{})
+		if plugin["id"] != "file-parser" || plugin["pdf"].(map[string]interface{})["engine"] != "mistral-ocr" {
+			t.Errorf("unexpected plugin: %v", plugin)
+		}
+
+		content := req["messages"].([]interface{})[0].(map[string]interface{})["content"].([]interface{})
+		file := content[1].(map[string]interface{})
+		if file["type"] != "file" || file["file"].(map[string]interface{})["filename"] != "report.pdf" {
+			t.Errorf("unexpected file part: %v", file)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chat-f","choices":[{"message":{"role":"assistant","content":"A report.",` +
+			`"annotations":[{"type":"file","file":{"hash":"abc123","name":"report.pdf","content":[{"type":"text","text":"Parsed"}]}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.ChatComplete(context.Background(),
+		[]Message{CreateFileMessage("user", "Summarize", NewFilePart("report.pdf", testPDF))},
+		WithModel("google/gemma-3-27b-it"),
+		WithPlugins(NewFileParserPlugin(PDFEngineMistralOCR)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	annotations := resp.Choices[0].Message.Annotations
+	if len(annotations) != 1 || annotations[0].File == nil || annotations[0].File.Hash != "abc123" {
+		t.Fatalf("expected file annotation, got %+v", annotations)
+	}
+	if content := annotations[0].File.Content; len(content) != 1 || content[0].Text != "Parsed" {
+		t.Errorf("unexpected parsed content: %+v", content)
+	}
+}
@@ -0,0 +1,91 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Polling settings for generation stats, which become available shortly after
+// a completion finishes.
+const (
+	generationPollRetries      = 6
+	generationPollInitialDelay = 500 * time.Millisecond
+	generationPollMaxDelay     = 4 * time.Second
+)
+
+// GetGeneration retrieves the stats of a generation by its ID, which is the ID of
+// the completion response. The stats include native token counts, the billed cost,
+// the provider used and latency.
+//
+// Stats become available shortly after a generation finishes; until then the API
+// responds with a not found error.
+//
+// Example:
+//
+//	resp, err := client.ChatComplete(ctx, messages, openrouter.WithModel("openai/gpt-4o"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	generation, err := client.GetGeneration(ctx, resp.ID)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("Cost: $%.6f via %s\n", generation.Data.TotalCost, generation.Data.ProviderName)
+func (c *Client) GetGeneration(ctx context.Context, id string) (*GenerationResponse, error) {
+	if id == "" {
+		return nil, &ValidationError{Field: "id", Message: "generation ID is required"}
+	}
+
+	endpoint := "/generation?id=" + url.QueryEscape(id)
+
+	var response GenerationResponse
+	if err := c.doRequest(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// waitForGeneration polls GetGeneration until the stats for id are available.
+func (c *Client) waitForGeneration(ctx context.Context, id string) (*GenerationData, error) {
+	config := &RetryConfig{
+		MaxRetries:   generationPollRetries,
+		InitialDelay: generationPollInitialDelay,
+		MaxDelay:     generationPollMaxDelay,
+		Multiplier:   defaultMultiplier,
+		RetryableError: func(err error) bool {
+			reqErr, ok := IsRequestError(err)
+			return ok && reqErr.IsNotFoundError()
+		},
+	}
+
+	var generation *GenerationResponse
+	err := RetryWithBackoff(ctx, config, func() error {
+		var err error
+		generation, err = c.GetGeneration(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &generation.Data, nil
+}
+
+// attachGenerationStats fetches the generation stats for a completed request.
+// The error wraps the underlying failure and is attached to the response.
+func (c *Client) attachGenerationStats(ctx context.Context, id string) (*GenerationData, error) {
+	if id == "" {
+		return nil, errors.New("failed to fetch generation stats: response has no ID")
+	}
+
+	generation, err := c.waitForGeneration(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch generation stats: %w", err)
+	}
+
+	return generation, nil
+}
@@ -0,0 +1,184 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const generationJSON = `{"data":{
+	"id":"gen-123",
+	"total_cost":0.00042,
+	"cache_discount":null,
+	"upstream_inference_cost":0.0004,
+	"usage":0.00042,
+	"is_byok":false,
+	"created_at":"2025-01-01T00:00:00Z",
+	"model":"openai/gpt-4o",
+	"app_id":null,
+	"streamed":false,
+	"cancelled":false,
+	"provider_name":"OpenAI",
+	"latency":850,
+	"moderation_latency":null,
+	"generation_time":800,
+	"finish_reason":"stop",
+	"native_finish_reason":"stop",
+	"tokens_prompt":10,
+	"tokens_completion":20,
+	"native_tokens_prompt":11,
+	"native_tokens_completion":21,
+	"native_tokens_reasoning":0,
+	"native_tokens_cached":4,
+	"num_media_prompt":null,
+	"num_media_completion":null,
+	"num_search_results":null,
+	"origin":"https://example.com"
+}}`
+
+func TestGetGeneration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("expected GET request, got %s", r.Method)
+		}
+		if r.URL.Path != "/generation" {
+			t.Errorf("expected path /generation, got %s", r.URL.Path)
+		}
+		if id := r.URL.Query().Get("id"); id != "gen-123" {
+			t.Errorf("expected id gen-123, got %q", id)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(generationJSON))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.GetGeneration(context.Background(), "gen-123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := resp.Data
+	if data.ID != "gen-123" || data.TotalCost != 0.00042 || data.ProviderName != "OpenAI" {
+		t.Errorf("unexpected generation: %+v", data)
+	}
+	if data.CacheDiscount != nil {
+		t.Errorf("expected nil cache discount, got %v", *data.CacheDiscount)
+	}
+	if data.UpstreamInferenceCost == nil || *data.UpstreamInferenceCost != 0.0004 {
+		t.Errorf("unexpected upstream inference cost: %v", data.UpstreamInferenceCost)
+	}
+	if data.Latency == nil || *data.Latency != 850 {
+		t.Errorf("unexpected latency: %v", data.Latency)
+	}
+	if data.NativeTokensCached == nil || *data.NativeTokensCached != 4 {
+		t.Errorf("unexpected cached tokens: %v", data.NativeTokensCached)
+	}
+
+	if _, err := client.GetGeneration(context.Background(), ""); err == nil {
+		t.Error("expected error for empty ID")
+	} else if _, ok := IsValidationError(err); !ok {
+		t.Errorf("expected ValidationError, got %T", err)
+	}
+}
+
+func TestWithGenerationStats(t *testing.T) {
+	generationCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/chat/completions":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if _, ok := body["generationStats"]; ok {
+				t.Error("client-side setting must not be sent to the API")
+			}
+
+			resp := finalResponse("hello")
+			resp.ID = "gen-123"
+			json.NewEncoder(w).Encode(resp)
+		case "/generation":
+			generationCalls++
+			// Stats are not available on the first poll
+			if generationCalls == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":{"message":"Generation not found"}}`))
+				return
+			}
+			w.Write([]byte(generationJSON))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("openai/gpt-4o"),
+		WithGenerationStats(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if generationCalls != 2 {
+		t.Errorf("expected stats to be polled twice, got %d", generationCalls)
+	}
+	if resp.Generation == nil || resp.Generation.TotalCost != 0.00042 {
+		t.Errorf("expected generation stats to be attached, got %+v", resp.Generation)
+	}
+
+	// Without the option, no stats are fetched
+	generationCalls = 0
+	resp, err = client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("openai/gpt-4o"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if generationCalls != 0 || resp.Generation != nil {
+		t.Error("expected no generation stats without WithGenerationStats")
+	}
+}
+
+func TestWithGenerationStatsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/completions":
+			json.NewEncoder(w).Encode(CompletionResponse{
+				ID:      "gen-456",
+				Choices: []CompletionChoice{{Text: "hello"}},
+			})
+		case "/generation":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"message":"Forbidden"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.Complete(context.Background(), "Once upon a time",
+		WithCompletionModel("openai/gpt-3.5-turbo-instruct"),
+		WithCompletionGenerationStats(),
+	)
+	if err != nil {
+		t.Fatalf("expected the completion to succeed, got %v", err)
+	}
+	if resp.ID != "gen-456" || resp.Generation != nil {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	statsErr := resp.GenerationErr
+	if statsErr == nil || !strings.Contains(statsErr.Error(), "generation stats") {
+		t.Fatalf("expected generation stats error, got %v", statsErr)
+	}
+	if reqErr, ok := IsRequestError(statsErr); !ok || reqErr.StatusCode != http.StatusForbidden {
+		t.Errorf("expected wrapped RequestError, got %v", statsErr)
+	}
+}
@@ -0,0 +1,242 @@
+package openrouter
+
+import (
+	"context"
+	"time"
+)
+
+// defaultHedgeRequests is the number of requests a hedge launches when MaxRequests is not set.
+const defaultHedgeRequests = 2
+
+// HedgeConfig configures hedged requests. A hedged request launches another request
+// when the previous ones have not answered within Delay, uses the first successful
+// answer and cancels the rest. This trades extra spend for lower tail latency.
+type HedgeConfig struct {
+	// Delay is how long to wait for an answer before launching the next request.
+	// For streams, a request has answered once it produces its first event.
+	Delay time.Duration
+	// MaxRequests is the total number of requests that may be launched, including
+	// the first. Defaults to 2.
+	MaxRequests int
+	// Providers optionally routes the hedged requests to other providers: the
+	// second request uses the first provider, the third the second, and so on,
+	// wrapping around. The first request keeps the request's own provider settings.
+	Providers []Provider
+}
+
+// HedgeReport describes the requests made by a hedged call, for accounting of the
+// extra spend.
+type HedgeReport struct {
+	// Requests is the number of requests launched
+	Requests int
+	// Winner is the index of the request whose answer was used, 0 being the first
+	Winner int
+	// Canceled is the number of requests canceled after the winner answered. The
+	// provider may still bill the tokens they generated.
+	Canceled int
+	// Failed is the number of requests that failed
+	Failed int
+}
+
+// Wasted returns the number of requests whose answer was not used.
+func (r *HedgeReport) Wasted() int {
+	return r.Canceled + r.Failed
+}
+
+// WithHedging sends the request as a hedged request. It applies to ChatComplete and
+// ChatCompleteStream; the report is available from the response's Hedge field or the
+// stream's Hedge method. Launches are not retried: a request that fails launches the
+// next one immediately, and the client's retry settings do not apply.
+//
+// Example:
+//
+//	resp, err := client.ChatComplete(ctx, messages,
+//	    openrouter.WithModel("openai/gpt-4o-mini"),
+//	    openrouter.WithHedging(openrouter.HedgeConfig{
+//	        Delay:     800 * time.Millisecond,
+//	        Providers: []openrouter.Provider{{Order: []string{"azure"}}},
+//	    }),
+//	)
+//	if err == nil && resp.Hedge.Wasted() > 0 {
+//	    log.Printf("hedging used %d extra requests", resp.Hedge.Wasted())
+//	}
+func WithHedging(config HedgeConfig) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		r.hedge = &config
+	}
+}
+
+// maxRequests returns the number of requests the hedge may launch.
+func (h *HedgeConfig) maxRequests() int {
+	if h.MaxRequests < 1 {
+		return defaultHedgeRequests
+	}
+	return h.MaxRequests
+}
+
+// hedgeRequest returns the request sent by the hedge's index-th launch.
+func (r *ChatCompletionRequest) hedgeRequest(index int) *ChatCompletionRequest {
+	hedged := *r
+	hedged.hedge = nil
+
+	if providers := r.hedge.Providers; index > 0 && len(providers) > 0 {
+		provider := providers[(index-1)%len(providers)]
+		hedged.Provider = &provider
+	}
+
+	return &hedged
+}
+
+// hedgeResult is the outcome of a single launch of a hedge.
+type hedgeResult[T any] struct {
+	index  int
+	value  T
+	err    error
+	cancel context.CancelFunc
+}
+
+// runHedge launches requests with launch until one succeeds, following the hedge
+// configuration of req. Launches that lose are canceled, and values they still
+// return are passed to discard. The winning launch's context stays alive until
+// the returned cancel function is called.
+func runHedge[T any](ctx context.Context, req *ChatCompletionRequest, launch func(ctx context.Context, req *ChatCompletionRequest) (T, error), discard func(T)) (T, context.CancelFunc, *HedgeReport, error) {
+	maxRequests := req.hedge.maxRequests()
+	results := make(chan hedgeResult[T], maxRequests)
+	cancels := make([]context.CancelFunc, 0, maxRequests)
+
+	start := func() {
+		index := len(cancels)
+		launchCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+
+		go func() {
+			value, err := launch(launchCtx, req.hedgeRequest(index))
+			results <- hedgeResult[T]{index: index, value: value, err: err, cancel: cancel}
+		}()
+	}
+
+	timer := time.NewTimer(req.hedge.Delay)
+	defer timer.Stop()
+
+	report := &HedgeReport{}
+	start()
+	pending := 1
+	var lastErr error
+
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) < maxRequests && ctx.Err() == nil {
+				start()
+				pending++
+				timer.Reset(req.hedge.Delay)
+			}
+
+		case result := <-results:
+			pending--
+
+			if result.err == nil {
+				report.Requests = len(cancels)
+				report.Winner = result.index
+				report.Canceled = pending
+
+				for i, cancel := range cancels {
+					if i != result.index {
+						cancel()
+					}
+				}
+
+				// Discard the values of canceled launches that still succeed
+				go func(pending int) {
+					for i := 0; i < pending; i++ {
+						if late := <-results; late.err == nil {
+							discard(late.value)
+						}
+					}
+				}(pending)
+
+				return result.value, result.cancel, report, nil
+			}
+
+			result.cancel()
+			report.Failed++
+			lastErr = result.err
+
+			// A failed request is replaced right away
+			if len(cancels) < maxRequests && ctx.Err() == nil {
+				start()
+				pending++
+				timer.Reset(req.hedge.Delay)
+			} else if pending == 0 {
+				var zero T
+				return zero, nil, report, lastErr
+			}
+		}
+	}
+}
+
+// hedgeChatComplete sends a hedged chat completion request and stores the winning
+// response in resp.
+func (c *Client) hedgeChatComplete(ctx context.Context, req *ChatCompletionRequest, resp *ChatCompletionResponse) error {
+	// Further launches take the place of retries, so every launch is in the report
+	ctx = ContextWithRetryConfig(ctx, &RetryConfig{MaxRetries: 0})
+
+	winner, cancel, report, err := runHedge(ctx, req,
+		func(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+			var resp ChatCompletionResponse
+			if err := c.doRequest(ctx, "POST", "/chat/completions", req, &resp); err != nil {
+				return nil, err
+			}
+			return &resp, nil
+		},
+		func(*ChatCompletionResponse) {},
+	)
+	if err != nil {
+		return err
+	}
+	cancel()
+
+	*resp = *winner
+	resp.Hedge = report
+	return nil
+}
+
+// hedgeStream opens a hedged stream. A stream wins once it produces its first event,
+// or ends without error before producing any.
+func (c *Client) hedgeStream(ctx context.Context, endpoint string, req *ChatCompletionRequest) (*eventStream, error) {
+	winner, cancel, report, err := runHedge(ctx, req,
+		func(ctx context.Context, req *ChatCompletionRequest) (*eventStream, error) {
+			stream, err := c.createStream(ctx, endpoint, req)
+			if err != nil {
+				return nil, err
+			}
+
+			select {
+			case <-stream.firstEvent:
+				return stream, nil
+			case <-stream.done:
+				if err := stream.Err(); err != nil {
+					stream.Close()
+					return nil, err
+				}
+				return stream, nil
+			}
+		},
+		func(stream *eventStream) {
+			stream.Close()
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Closing the stream releases the context of its launch
+	streamCancel := winner.cancel
+	winner.cancel = func() {
+		streamCancel()
+		cancel()
+	}
+	winner.hedge = report
+
+	return winner, nil
+}
@@ -0,0 +1,198 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hedgeServer answers slowly unless the request is routed to the "fast" provider.
+func hedgeServer(t *testing.T, canceled *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		fast := req.Provider != nil && len(req.Provider.Order) > 0 && req.Provider.Order[0] == "fast"
+		if !fast {
+			select {
+			case <-r.Context().Done():
+				canceled.Add(1)
+				return
+			case <-time.After(2 * time.Second):
+			}
+		}
+
+		if req.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte(`data: {"id":"h","choices":[{"index":0,"delta":{"role":"assistant","content":"fast"}}]}` + "\n\n"))
+			w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse("fast"))
+	}))
+}
+
+func TestChatCompleteHedging(t *testing.T) {
+	var canceled atomic.Int32
+	server := hedgeServer(t, &canceled)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	start := time.Now()
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithHedging(HedgeConfig{
+			Delay:     50 * time.Millisecond,
+			Providers: []Provider{{Order: []string{"fast"}}},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the hedged request to answer quickly, took %v", elapsed)
+	}
+
+	if resp.Choices[0].Message.Content != "fast" {
+		t.Errorf("unexpected content: %v", resp.Choices[0].Message.Content)
+	}
+	report := resp.Hedge
+	if report == nil || report.Requests != 2 || report.Winner != 1 || report.Canceled != 1 || report.Wasted() != 1 {
+		t.Fatalf("unexpected hedge report: %+v", report)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for canceled.Load() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if canceled.Load() != 1 {
+		t.Error("expected the slow request to be canceled")
+	}
+}
+
+func TestChatCompleteHedgingFastPrimary(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse("ok"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithHedging(HedgeConfig{Delay: time.Second, MaxRequests: 3}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Hedge.Requests != 1 || resp.Hedge.Wasted() != 0 || requests.Load() != 1 {
+		t.Errorf("expected a single request, got %+v (%d sent)", resp.Hedge, requests.Load())
+	}
+}
+
+func TestChatCompleteHedgingFailures(t *testing.T) {
+	var requests atomic.Int32
+	var failAll atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 || failAll.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"error":{"message":"upstream error"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse("ok"))
+	}))
+	defer server.Close()
+
+	// Launches are not retried, so the client's backoff does not delay the next one
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithRetry(3, time.Second))
+
+	// The failed request is replaced without waiting for the delay
+	start := time.Now()
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithHedging(HedgeConfig{Delay: 5 * time.Second}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected the failed request to be replaced immediately")
+	}
+	if resp.Hedge.Failed != 1 || resp.Hedge.Winner != 1 || requests.Load() != 2 {
+		t.Errorf("unexpected hedge report: %+v (%d sent)", resp.Hedge, requests.Load())
+	}
+
+	// When every request fails, the last error is returned
+	failAll.Store(true)
+	requests.Store(0)
+	_, err = client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithHedging(HedgeConfig{Delay: time.Millisecond, MaxRequests: 2}),
+	)
+	if reqErr, ok := IsRequestError(err); !ok || reqErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected the request error, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected one request per launch, got %d", requests.Load())
+	}
+}
+
+func TestChatCompleteStreamHedging(t *testing.T) {
+	var canceled atomic.Int32
+	server := hedgeServer(t, &canceled)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithHedging(HedgeConfig{
+			Delay:     50 * time.Millisecond,
+			Providers: []Provider{{Order: []string{"fast"}}},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	resp, err := AccumulateChatStream(stream)
+	if err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "fast" {
+		t.Errorf("unexpected content: %v", resp.Choices[0].Message.Content)
+	}
+
+	report := stream.Hedge()
+	if report == nil || report.Requests != 2 || report.Winner != 1 || report.Canceled != 1 {
+		t.Errorf("unexpected hedge report: %+v", report)
+	}
+
+	if plain, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"), WithProvider(Provider{Order: []string{"fast"}})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else {
+		if plain.Hedge() != nil {
+			t.Error("expected no hedge report without hedging")
+		}
+		plain.Close()
+	}
+}
@@ -0,0 +1,84 @@
+package openrouter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+
+	// Register decoders for the formats image models return
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// MessageImage is an image generated by the model.
+type MessageImage struct {
+	// Type is the image type; always "image_url"
+	Type string `json:"type"`
+	// ImageURL contains the image as a base64 data URL
+	ImageURL ImageURL `json:"image_url"`
+}
+
+// Bytes returns the encoded image and its MIME type (e.g. "image/png").
+func (m MessageImage) Bytes() ([]byte, string, error) {
+	return decodeDataURL(m.ImageURL.URL)
+}
+
+// Image decodes the image. PNG, JPEG and GIF images are supported; decoders for
+// other formats can be registered with image.RegisterFormat.
+func (m MessageImage) Image() (image.Image, error) {
+	data, mimeType, err := m.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s image: %w", mimeType, err)
+	}
+
+	return img, nil
+}
+
+// WriteFile writes the encoded image to path without re-encoding it. Use the MIME
+// type from Bytes to choose the file extension.
+func (m MessageImage) WriteFile(path string) error {
+	data, _, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+
+	return nil
+}
+
+// decodeDataURL decodes a base64 data URL into its contents and MIME type.
+func decodeDataURL(url string) ([]byte, string, error) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return nil, "", fmt.Errorf("not a data URL: %.32q", url)
+	}
+
+	header, payload, ok := strings.Cut(rest, ",")
+	if !ok {
+		return nil, "", fmt.Errorf("malformed data URL: missing data")
+	}
+
+	mimeType, ok := strings.CutSuffix(header, ";base64")
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported data URL encoding: %q", header)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode data URL: %w", err)
+	}
+
+	return data, mimeType, nil
+}
@@ -0,0 +1,137 @@
+package openrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	img.Set(1, 2, color.RGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestChatCompleteImageOutput(t *testing.T) {
+	pngData := testPNG(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		if len(req.Modalities) != 2 || req.Modalities[0] != ModalityImage || req.Modalities[1] != ModalityText {
+			t.Errorf("unexpected modalities: %v", req.Modalities)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ChatCompletionResponse{
+			ID: "chat-img",
+			Choices: []Choice{{Message: Message{
+				Role:    "assistant",
+				Content: "Here is your image.",
+				Images: []MessageImage{{
+					Type:     "image_url",
+					ImageURL: ImageURL{URL: dataURL("image/png", pngData)},
+				}},
+			}}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	resp, err := client.ChatComplete(context.Background(),
+		[]Message{CreateUserMessage("Draw a red pixel")},
+		WithModel("google/gemini-2.5-flash-image-preview"),
+		WithImageOutput(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	images := resp.Choices[0].Message.Images
+	if len(images) != 1 {
+		t.Fatalf("expected 1 image, got %d", len(images))
+	}
+
+	data, mimeType, err := images[0].Bytes()
+	if err != nil || mimeType != "image/png" || !bytes.Equal(data, pngData) {
+		t.Errorf("unexpected image bytes (%s, %v)", mimeType, err)
+	}
+
+	img, err := images[0].Image()
+	if err != nil {
+		t.Fatalf("failed to decode image: %v", err)
+	}
+	if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 3 {
+		t.Errorf("unexpected image bounds %v", img.Bounds())
+	}
+	if _, _, _, a := img.At(1, 2).RGBA(); a == 0 {
+		t.Error("expected the red pixel to be set")
+	}
+
+	path := filepath.Join(t.TempDir(), "out.png")
+	if err := images[0].WriteFile(path); err != nil {
+		t.Fatalf("failed to write image: %v", err)
+	}
+	written, _ := os.ReadFile(path)
+	if !bytes.Equal(written, pngData) {
+		t.Error("expected written file to match the image")
+	}
+}
+
+func TestMessageImageInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"remote URL", "https://example.com/image.png"},
+		{"missing data", "data:image/png;base64"},
+		{"not base64", "data:image/png,rawdata"},
+		{"invalid base64", "data:image/png;base64,!!!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := MessageImage{Type: "image_url", ImageURL: ImageURL{URL: tt.url}}
+			if _, _, err := img.Bytes(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	img := MessageImage{ImageURL: ImageURL{URL: dataURL("image/png", []byte("not a png"))}}
+	if _, err := img.Image(); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestChatStreamAccumulatorImages(t *testing.T) {
+	image := MessageImage{Type: "image_url", ImageURL: ImageURL{URL: "data:image/png;base64,AAAA"}}
+
+	acc := NewChatStreamAccumulator()
+	acc.Add(ChatCompletionResponse{Choices: []Choice{{Delta: &Message{Role: "assistant", Content: "Here"}}}})
+	acc.Add(ChatCompletionResponse{Choices: []Choice{{Delta: &Message{Images: []MessageImage{image}}}}})
+
+	images := acc.Response().Choices[0].Message.Images
+	if len(images) != 1 || images[0].ImageURL.URL != image.ImageURL.URL {
+		t.Errorf("expected streamed image, got %+v", images)
+	}
+}
@@ -0,0 +1,88 @@
+package openrouter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Operation describes an API call reported to an Instrumentation.
+type Operation struct {
+	// Method is the HTTP method (GET, POST, PATCH, DELETE)
+	Method string
+	// Endpoint is the path relative to the base URL, including any query string
+	Endpoint string
+	// Request is the request body, such as *ChatCompletionRequest; nil for requests without a body
+	Request interface{}
+	// Stream reports whether the call is a streaming request
+	Stream bool
+}
+
+// Instrumentation observes the API calls made by a client. It lets tracing and
+// metrics integrations, such as the otel subpackage, hook into the client
+// without the core package depending on them.
+type Instrumentation interface {
+	// StartOperation is called when an API call begins. The returned context is
+	// used for the call's requests, so it can carry e.g. a tracing span.
+	StartOperation(ctx context.Context, op Operation) (context.Context, OperationObserver)
+}
+
+// OperationObserver receives the events of a single API call.
+type OperationObserver interface {
+	// Retry is called before each retry with the upcoming attempt number
+	// (starting at 1), the error that triggered it and the delay.
+	Retry(attempt int, err error, delay time.Duration)
+	// StreamChunk is called with each decoded chunk of a streaming call, such
+	// as a ChatCompletionResponse, when the consumer reads it. received is when
+	// the chunk arrived, which is earlier if the consumer reads slowly.
+	StreamChunk(chunk interface{}, received time.Time)
+	// End is called once when the call finishes. For non-streaming calls,
+	// response is the decoded response, such as *ChatCompletionResponse; for
+	// streams it is nil and the chunks have been passed to StreamChunk.
+	End(response interface{}, err error)
+}
+
+// startOperation reports the start of an API call to the client's instrumentation.
+// The returned observer is nil when no instrumentation is configured.
+func (c *Client) startOperation(ctx context.Context, op Operation) (context.Context, OperationObserver) {
+	if c.instrumentation == nil {
+		return ctx, nil
+	}
+
+	ctx, observer := c.instrumentation.StartOperation(ctx, op)
+	if observer == nil {
+		return ctx, nil
+	}
+
+	return ctx, &onceObserver{OperationObserver: observer}
+}
+
+// onceObserver guarantees End is reported only once.
+type onceObserver struct {
+	OperationObserver
+	once sync.Once
+}
+
+func (o *onceObserver) End(response interface{}, err error) {
+	o.once.Do(func() {
+		o.OperationObserver.End(response, err)
+	})
+}
+
+// observeRetries returns a copy of config that also reports retries to the observer.
+func observeRetries(config *RetryConfig, observer OperationObserver) *RetryConfig {
+	if observer == nil {
+		return config
+	}
+
+	observed := *config
+	onRetry := config.OnRetry
+	observed.OnRetry = func(attempt int, err error, delay time.Duration) {
+		observer.Retry(attempt, err, delay)
+		if onRetry != nil {
+			onRetry(attempt, err, delay)
+		}
+	}
+
+	return &observed
+}
@@ -0,0 +1,173 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingInstrumentation struct {
+	mu         sync.Mutex
+	operations []Operation
+	observers  []*recordingObserver
+}
+
+func (r *recordingInstrumentation) StartOperation(ctx context.Context, op Operation) (context.Context, OperationObserver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	observer := &recordingObserver{}
+	r.operations = append(r.operations, op)
+	r.observers = append(r.observers, observer)
+	return ctx, observer
+}
+
+type recordingObserver struct {
+	mu       sync.Mutex
+	retries  []int
+	chunks   int
+	ends     int
+	response interface{}
+	err      error
+}
+
+func (o *recordingObserver) Retry(attempt int, err error, delay time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retries = append(o.retries, attempt)
+}
+
+func (o *recordingObserver) StreamChunk(chunk interface{}, received time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.chunks++
+}
+
+func (o *recordingObserver) End(response interface{}, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ends++
+	o.response = response
+	o.err = err
+}
+
+func TestInstrumentationRequest(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"message":"unavailable"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse("hello"))
+	}))
+	defer server.Close()
+
+	var userRetries int
+	instrumentation := &recordingInstrumentation{}
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetryConfig(&RetryConfig{
+			MaxRetries:   2,
+			InitialDelay: time.Millisecond,
+			OnRetry: func(attempt int, err error, delay time.Duration) {
+				userRetries++
+			},
+		}),
+		WithInstrumentation(instrumentation),
+	)
+
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(instrumentation.operations) != 1 {
+		t.Fatalf("expected 1 operation, got %d", len(instrumentation.operations))
+	}
+	op := instrumentation.operations[0]
+	if op.Method != "POST" || op.Endpoint != "/chat/completions" || op.Stream {
+		t.Errorf("unexpected operation: %+v", op)
+	}
+	if req, ok := op.Request.(*ChatCompletionRequest); !ok || req.Model != "test-model" {
+		t.Errorf("expected typed request, got %T", op.Request)
+	}
+
+	observer := instrumentation.observers[0]
+	if len(observer.retries) != 1 || observer.retries[0] != 1 {
+		t.Errorf("expected one retry to be observed, got %v", observer.retries)
+	}
+	if userRetries != 1 {
+		t.Errorf("expected the configured OnRetry to still be called, got %d calls", userRetries)
+	}
+	if observer.ends != 1 || observer.err != nil {
+		t.Errorf("expected a single successful end, got %d (err=%v)", observer.ends, observer.err)
+	}
+	if got, ok := observer.response.(*ChatCompletionResponse); !ok || got.ID != resp.ID {
+		t.Errorf("expected decoded response, got %#v", observer.response)
+	}
+}
+
+func TestInstrumentationStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n"))
+		w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	instrumentation := &recordingInstrumentation{}
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithInstrumentation(instrumentation))
+
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for range stream.Events() {
+	}
+	stream.Close()
+
+	if len(instrumentation.operations) != 1 || !instrumentation.operations[0].Stream {
+		t.Fatalf("expected one streaming operation, got %+v", instrumentation.operations)
+	}
+
+	observer := instrumentation.observers[0]
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+
+	if observer.chunks != 2 {
+		t.Errorf("expected 2 chunks, got %d", observer.chunks)
+	}
+	if observer.ends != 1 {
+		t.Errorf("expected end to be reported once, got %d", observer.ends)
+	}
+}
+
+func TestInstrumentationStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid key"}}`))
+	}))
+	defer server.Close()
+
+	instrumentation := &recordingInstrumentation{}
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithInstrumentation(instrumentation))
+
+	_, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	observer := instrumentation.observers[0]
+	if observer.ends != 1 || observer.err != err {
+		t.Errorf("expected end with the request error, got %d (err=%v)", observer.ends, observer.err)
+	}
+}
@@ -0,0 +1,158 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces the values of headers that carry credentials.
+const redactedValue = "[REDACTED]"
+
+// loggedBodyEndpoints are the endpoints whose bodies WithBodyLogging logs.
+var loggedBodyEndpoints = map[string]bool{
+	"/chat/completions": true,
+	"/completions":      true,
+}
+
+// sensitiveHeaders are redacted whenever headers are logged.
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"X-Api-Key":     true,
+}
+
+// WithLogger logs requests, responses, retries, stream reconnects and errors to
+// the given logger. Request starts and headers are logged at debug level,
+// completed requests at info, retries and reconnects at warn, and failures at
+// error level. Credentials in headers are always redacted.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithBodyLogging enables debug logging of request and response bodies of chat and
+// completion requests, truncated to maxBytes each. Bodies may contain prompts and
+// completions, so this is off by default; streamed response bodies are never logged.
+// Other endpoints are not logged, as their bodies may hold secrets such as the keys
+// returned by CreateKey. It has no effect without WithLogger.
+func WithBodyLogging(maxBytes int) ClientOption {
+	return func(c *Client) {
+		c.logBodyLimit = maxBytes
+	}
+}
+
+// loggedHeaders logs headers with credentials redacted.
+type loggedHeaders http.Header
+
+// LogValue implements slog.LogValuer.
+func (h loggedHeaders) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(h))
+	for key, values := range h {
+		value := strings.Join(values, ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(key)] {
+			value = redactedValue
+		}
+		attrs = append(attrs, slog.String(key, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// truncateBody shortens a body to limit bytes for logging.
+func truncateBody(body []byte, limit int) string {
+	if len(body) <= limit {
+		return string(body)
+	}
+	return string(body[:limit]) + "...(truncated)"
+}
+
+// logRequests is the middleware installed by WithLogger. It runs innermost, so it
+// logs each HTTP attempt with the headers that are actually sent.
+func (c *Client) logRequests(next RoundTripFunc) RoundTripFunc {
+	return func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+		attrs := []any{
+			slog.String("method", req.Method),
+			slog.String("endpoint", req.Endpoint),
+			slog.Bool("stream", req.Stream),
+		}
+
+		logBodies := c.logBodyLimit > 0 && loggedBodyEndpoints[req.Endpoint]
+
+		debugAttrs := append([]any{slog.Any("headers", loggedHeaders(req.Header))}, attrs...)
+		if logBodies && req.Body != nil {
+			if data, err := json.Marshal(req.Body); err == nil {
+				debugAttrs = append(debugAttrs, slog.String("body", truncateBody(data, c.logBodyLimit)))
+			}
+		}
+		c.logger.DebugContext(ctx, "openrouter request started", debugAttrs...)
+
+		start := time.Now()
+		resp, err := next(ctx, req)
+		attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+		if err != nil {
+			if reqErr, ok := IsRequestError(err); ok {
+				attrs = append(attrs, slog.Int("status", reqErr.StatusCode))
+			}
+			attrs = append(attrs, slog.Any("error", err))
+			c.logger.ErrorContext(ctx, "openrouter request failed", attrs...)
+			return resp, err
+		}
+
+		if resp != nil {
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			if logBodies && len(resp.Body) > 0 {
+				c.logger.DebugContext(ctx, "openrouter response body",
+					slog.String("endpoint", req.Endpoint),
+					slog.String("body", truncateBody(resp.Body, c.logBodyLimit)),
+				)
+			}
+		}
+		c.logger.InfoContext(ctx, "openrouter request completed", attrs...)
+
+		return resp, nil
+	}
+}
+
+// logRetries returns a copy of config that also logs retry decisions.
+func (c *Client) logRetries(ctx context.Context, config *RetryConfig, method, endpoint string) *RetryConfig {
+	if c.logger == nil {
+		return config
+	}
+
+	logged := *config
+	onRetry := config.OnRetry
+	logged.OnRetry = func(attempt int, err error, delay time.Duration) {
+		c.logger.WarnContext(ctx, "openrouter request retrying",
+			slog.String("method", method),
+			slog.String("endpoint", endpoint),
+			slog.Int("attempt", attempt),
+			slog.Int("max_retries", config.MaxRetries),
+			slog.Duration("delay", delay),
+			slog.Any("error", err),
+		)
+		if onRetry != nil {
+			onRetry(attempt, err, delay)
+		}
+	}
+
+	return &logged
+}
+
+// logStreamEnd logs a stream that ended with an error. Streams closed by the
+// caller are not errors.
+func (c *Client) logStreamEnd(ctx context.Context, endpoint string, err error) {
+	if c.logger == nil || err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	c.logger.ErrorContext(ctx, "openrouter stream failed",
+		slog.String("endpoint", endpoint),
+		slog.Any("error", err),
+	)
+}
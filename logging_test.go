@@ -0,0 +1,219 @@
+package openrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return logger, &buf
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func findRecord(records []map[string]interface{}, msg string) map[string]interface{} {
+	for _, record := range records {
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestLoggerRequestsAndRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"error":{"message":"upstream error"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse("secret completion"))
+	}))
+	defer server.Close()
+
+	logger, buf := newTestLogger()
+	client := NewClient(WithAPIKey("sk-secret-key"),
+		WithBaseURL(server.URL),
+		WithRetry(1, time.Millisecond),
+		WithLogger(logger),
+	)
+
+	if _, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("secret prompt")}, WithModel("test-model")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "sk-secret-key") {
+		t.Error("expected API key to be redacted")
+	}
+	if strings.Contains(output, "secret prompt") || strings.Contains(output, "secret completion") {
+		t.Error("expected bodies not to be logged by default")
+	}
+
+	records := logRecords(t, buf)
+
+	started := findRecord(records, "openrouter request started")
+	if started == nil || started["level"] != "DEBUG" {
+		t.Fatalf("expected debug request start record, got %v", started)
+	}
+	headers, _ := started["headers"].(map[string]interface{})
+	if headers["Authorization"] != redactedValue {
+		t.Errorf("expected redacted Authorization header, got %v", headers["Authorization"])
+	}
+
+	failed := findRecord(records, "openrouter request failed")
+	if failed == nil || failed["level"] != "ERROR" || failed["status"] != float64(http.StatusBadGateway) {
+		t.Errorf("expected error record with status, got %v", failed)
+	}
+
+	retrying := findRecord(records, "openrouter request retrying")
+	if retrying == nil || retrying["level"] != "WARN" || retrying["attempt"] != float64(1) {
+		t.Errorf("expected warn retry record, got %v", retrying)
+	}
+
+	completed := findRecord(records, "openrouter request completed")
+	if completed == nil || completed["level"] != "INFO" || completed["status"] != float64(http.StatusOK) {
+		t.Errorf("expected info completion record, got %v", completed)
+	}
+	if _, ok := completed["duration"]; !ok {
+		t.Error("expected completion record to include the duration")
+	}
+}
+
+func TestLoggerBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	logger, buf := newTestLogger()
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithLogger(logger),
+		WithBodyLogging(40),
+	)
+
+	if _, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hello")}, WithModel("test-model")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records := logRecords(t, buf)
+
+	started := findRecord(records, "openrouter request started")
+	body, _ := started["body"].(string)
+	if !strings.Contains(body, "test-model") {
+		t.Errorf("expected request body to be logged, got %q", body)
+	}
+
+	response := findRecord(records, "openrouter response body")
+	if response == nil {
+		t.Fatal("expected response body record")
+	}
+	responseBody, _ := response["body"].(string)
+	if !strings.HasSuffix(responseBody, "...(truncated)") || len(responseBody) != 40+len("...(truncated)") {
+		t.Errorf("expected response body truncated to 40 bytes, got %q", responseBody)
+	}
+}
+
+func TestLoggerBodiesSkipKeyEndpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"name":"ci","label":"sk-or-v1-abc...xyz"},"key":"sk-or-v1-secret-new-key"}`))
+	}))
+	defer server.Close()
+
+	logger, buf := newTestLogger()
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithLogger(logger),
+		WithBodyLogging(1000),
+	)
+
+	resp, err := client.CreateKey(context.Background(), &CreateKeyRequest{Name: "ci"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Key != "sk-or-v1-secret-new-key" {
+		t.Fatalf("unexpected key: %q", resp.Key)
+	}
+
+	if strings.Contains(buf.String(), "secret-new-key") {
+		t.Errorf("expected the created key not to be logged, got %s", buf.String())
+	}
+	if findRecord(logRecords(t, buf), "openrouter request completed") == nil {
+		t.Error("expected the request to be logged")
+	}
+}
+
+func TestLoggedHeadersRedaction(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer sk-secret")
+	header.Set("X-Api-Key", "sk-other")
+	header.Set("X-Title", "My App")
+
+	value := loggedHeaders(header).LogValue()
+	for _, attr := range value.Group() {
+		switch attr.Key {
+		case "Authorization", "X-Api-Key":
+			if attr.Value.String() != redactedValue {
+				t.Errorf("expected %s to be redacted, got %q", attr.Key, attr.Value.String())
+			}
+		case "X-Title":
+			if attr.Value.String() != "My App" {
+				t.Errorf("unexpected X-Title %q", attr.Value.String())
+			}
+		}
+	}
+}
+
+func TestLoggerStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {invalid json}\n\n"))
+	}))
+	defer server.Close()
+
+	logger, buf := newTestLogger()
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithLogger(logger))
+
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range stream.Events() {
+	}
+	stream.Close()
+
+	failed := findRecord(logRecords(t, buf), "openrouter stream failed")
+	if failed == nil || failed["level"] != "ERROR" {
+		t.Errorf("expected stream failure to be logged, got %v", failed)
+	}
+}
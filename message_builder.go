@@ -0,0 +1,314 @@
+package openrouter
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+// downscaleJPEGQuality is the quality used when re-encoding downscaled JPEG images.
+const downscaleJPEGQuality = 85
+
+// MessageBuilder builds a message from multiple content parts, kept in the order
+// they are added. Errors from reading files are reported by Build.
+//
+// Example:
+//
+//	msg, err := openrouter.NewMessage("user").
+//	    Text("Compare these two charts with the report.").
+//	    ImageFile("q1.png").
+//	    ImageURL("https://example.com/q2.png", "high").
+//	    File("report.pdf").
+//	    MaxImageDimension(1568).
+//	    BuildFor(model)
+type MessageBuilder struct {
+	role              string
+	parts             []builderPart
+	maxImageDimension int
+	maxImageBytes     int
+	err               error
+}
+
+// builderPart is either a finished content part or an image that is encoded on Build.
+type builderPart struct {
+	part  ContentPart
+	image *pendingImage
+}
+
+// pendingImage is an image whose encoding depends on the builder's size limits.
+type pendingImage struct {
+	data     []byte
+	mimeType string
+}
+
+// NewMessage starts building a message with the given role.
+func NewMessage(role string) *MessageBuilder {
+	return &MessageBuilder{role: role}
+}
+
+// Text adds a text part.
+func (b *MessageBuilder) Text(text string) *MessageBuilder {
+	return b.Part(ContentPart{Type: "text", Text: text})
+}
+
+// ImageURL adds an image by URL. Detail is "low", "high", "auto" or empty.
+func (b *MessageBuilder) ImageURL(url string, detail string) *MessageBuilder {
+	return b.Part(ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: url, Detail: detail}})
+}
+
+// ImageBytes adds an encoded image, sent as a base64 data URL. If mimeType is
+// empty, it is detected from the data.
+func (b *MessageBuilder) ImageBytes(data []byte, mimeType string) *MessageBuilder {
+	if mimeType == "" {
+		mimeType = detectMIMEType("", data)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return b.fail(&ValidationError{Field: "content", Message: fmt.Sprintf("unsupported image type %q", mimeType)})
+	}
+
+	b.parts = append(b.parts, builderPart{image: &pendingImage{data: data, mimeType: mimeType}})
+	return b
+}
+
+// ImageFile adds the image at path, sent as a base64 data URL.
+func (b *MessageBuilder) ImageFile(path string) *MessageBuilder {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b.fail(fmt.Errorf("failed to read image: %w", err))
+	}
+
+	return b.ImageBytes(data, detectMIMEType(path, data))
+}
+
+// File adds the file at path, such as a PDF.
+func (b *MessageBuilder) File(path string) *MessageBuilder {
+	part, err := NewFilePartFromPath(path)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.Part(part)
+}
+
+// FileBytes adds a file from its contents.
+func (b *MessageBuilder) FileBytes(filename string, data []byte) *MessageBuilder {
+	return b.Part(NewFilePart(filename, data))
+}
+
+// Audio adds the WAV or MP3 audio file at path.
+func (b *MessageBuilder) Audio(path string) *MessageBuilder {
+	part, err := NewAudioPartFromPath(path)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.Part(part)
+}
+
+// Part adds a content part as is.
+func (b *MessageBuilder) Part(part ContentPart) *MessageBuilder {
+	b.parts = append(b.parts, builderPart{part: part})
+	return b
+}
+
+// MaxImageDimension downscales images added from bytes or files so that neither
+// side exceeds pixels, preserving the aspect ratio. PNG, JPEG and GIF images can be
+// downscaled; JPEGs stay JPEG and other formats are re-encoded as PNG. Images in
+// other formats are sent unchanged.
+func (b *MessageBuilder) MaxImageDimension(pixels int) *MessageBuilder {
+	b.maxImageDimension = pixels
+	return b
+}
+
+// MaxImageBytes makes Build fail if an image added from bytes or files is larger
+// than maxBytes after downscaling.
+func (b *MessageBuilder) MaxImageBytes(maxBytes int) *MessageBuilder {
+	b.maxImageBytes = maxBytes
+	return b
+}
+
+// Build returns the message, or the first error encountered while adding parts.
+func (b *MessageBuilder) Build() (Message, error) {
+	if b.err != nil {
+		return Message{}, b.err
+	}
+	if len(b.parts) == 0 {
+		return Message{}, &ValidationError{Field: "content", Message: "message has no content"}
+	}
+
+	parts := make([]ContentPart, 0, len(b.parts))
+	for _, p := range b.parts {
+		if p.image == nil {
+			parts = append(parts, p.part)
+			continue
+		}
+
+		part, err := b.encodeImage(p.image)
+		if err != nil {
+			return Message{}, err
+		}
+		parts = append(parts, part)
+	}
+
+	return Message{Role: b.role, Content: parts}, nil
+}
+
+// BuildFor builds the message and validates it against the input modalities of
+// model, as returned by ListModels.
+func (b *MessageBuilder) BuildFor(model Model) (Message, error) {
+	msg, err := b.Build()
+	if err != nil {
+		return Message{}, err
+	}
+
+	if err := ValidateMessageModalities(msg, model); err != nil {
+		return Message{}, err
+	}
+
+	return msg, nil
+}
+
+// ValidateMessageModalities checks that model accepts every content part of msg.
+// Models without listed input modalities are not checked.
+func ValidateMessageModalities(msg Message, model Model) error {
+	accepted := model.Architecture.InputModalities
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	parts, ok := msg.Content.([]ContentPart)
+	if !ok {
+		return nil
+	}
+
+	for _, part := range parts {
+		modality := partModality(part)
+		if modality == "" || slices.Contains(accepted, modality) {
+			continue
+		}
+		return &ValidationError{
+			Field:   "content",
+			Message: fmt.Sprintf("model %s does not accept %s input (accepts %s)", model.ID, modality, strings.Join(accepted, ", ")),
+		}
+	}
+
+	return nil
+}
+
+// partModality returns the input modality a content part requires.
+func partModality(part ContentPart) string {
+	switch part.Type {
+	case "text":
+		return ModalityText
+	case "image_url":
+		return ModalityImage
+	case "file":
+		return ModalityFile
+	case "input_audio":
+		return ModalityAudio
+	}
+	return ""
+}
+
+// fail records the first error encountered while building.
+func (b *MessageBuilder) fail(err error) *MessageBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// encodeImage applies the size limits to an image and encodes it as a data URL part.
+func (b *MessageBuilder) encodeImage(img *pendingImage) (ContentPart, error) {
+	data, mimeType := img.data, img.mimeType
+
+	if b.maxImageDimension > 0 {
+		var err error
+		data, mimeType, err = downscaleImage(data, mimeType, b.maxImageDimension)
+		if err != nil {
+			return ContentPart{}, err
+		}
+	}
+
+	if b.maxImageBytes > 0 && len(data) > b.maxImageBytes {
+		return ContentPart{}, &ValidationError{
+			Field:   "content",
+			Message: fmt.Sprintf("image is %d bytes, exceeding the limit of %d bytes", len(data), b.maxImageBytes),
+		}
+	}
+
+	return ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: dataURL(mimeType, data)}}, nil
+}
+
+// downscaleImage shrinks an encoded image so that neither side exceeds maxDimension.
+// Images that already fit, or whose format cannot be decoded, are returned unchanged.
+func downscaleImage(data []byte, mimeType string, maxDimension int) ([]byte, string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (config.Width <= maxDimension && config.Height <= maxDimension) {
+		return data, mimeType, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	scale := float64(maxDimension) / float64(max(config.Width, config.Height))
+	width := max(1, int(math.Round(float64(config.Width)*scale)))
+	height := max(1, int(math.Round(float64(config.Height)*scale)))
+	dst := resizeBox(src, width, height)
+
+	var buf bytes.Buffer
+	if mimeType == "image/jpeg" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: downscaleJPEGQuality})
+	} else {
+		mimeType = "image/png"
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode downscaled image: %w", err)
+	}
+
+	return buf.Bytes(), mimeType, nil
+}
+
+// resizeBox downscales src to width x height by averaging the source pixels that
+// map to each destination pixel.
+func resizeBox(src image.Image, width, height int) *image.RGBA64 {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcH/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/height)
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcW/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,165 @@
+package openrouter
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("failed to encode JPEG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestMessageBuilderParts(t *testing.T) {
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "chart.png")
+	if err := os.WriteFile(imagePath, testPNG(t), 0o600); err != nil {
+		t.Fatalf("failed to write image: %v", err)
+	}
+	pdfPath := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(pdfPath, testPDF, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	msg, err := NewMessage("user").
+		Text("Compare").
+		ImageFile(imagePath).
+		ImageBytes(testJPEG(t, 4, 4), "").
+		ImageURL("https://example.com/chart.png", "high").
+		File(pdfPath).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if msg.Role != "user" {
+		t.Errorf("expected role 'user', got %q", msg.Role)
+	}
+	parts, ok := msg.Content.([]ContentPart)
+	if !ok || len(parts) != 5 {
+		t.Fatalf("expected 5 parts, got %+v", msg.Content)
+	}
+
+	if parts[0].Type != "text" || parts[0].Text != "Compare" {
+		t.Errorf("unexpected text part: %+v", parts[0])
+	}
+	if !strings.HasPrefix(parts[1].ImageURL.URL, "data:image/png;base64,") {
+		t.Errorf("expected PNG data URL, got %.40q", parts[1].ImageURL.URL)
+	}
+	if !strings.HasPrefix(parts[2].ImageURL.URL, "data:image/jpeg;base64,") {
+		t.Errorf("expected sniffed JPEG data URL, got %.40q", parts[2].ImageURL.URL)
+	}
+	if parts[3].ImageURL.URL != "https://example.com/chart.png" || parts[3].ImageURL.Detail != "high" {
+		t.Errorf("unexpected image URL part: %+v", parts[3].ImageURL)
+	}
+	if parts[4].Type != "file" || parts[4].File.Filename != "report.pdf" {
+		t.Errorf("unexpected file part: %+v", parts[4])
+	}
+}
+
+func TestMessageBuilderErrors(t *testing.T) {
+	if _, err := NewMessage("user").Build(); err == nil {
+		t.Error("expected error for empty message")
+	}
+
+	_, err := NewMessage("user").
+		Text("hi").
+		ImageFile(filepath.Join(t.TempDir(), "missing.png")).
+		File(filepath.Join(t.TempDir(), "missing.pdf")).
+		Build()
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "image") {
+		t.Errorf("expected the first error to be reported, got %v", err)
+	}
+
+	_, err = NewMessage("user").ImageBytes([]byte("plain text"), "").Build()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError for non-image data, got %v", err)
+	}
+}
+
+func TestMessageBuilderDownscale(t *testing.T) {
+	msg, err := NewMessage("user").
+		ImageBytes(testJPEG(t, 200, 100), "image/jpeg").
+		ImageBytes(testPNG(t), "image/png").
+		MaxImageDimension(50).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parts := msg.Content.([]ContentPart)
+	data, mimeType, err := decodeDataURL(parts[0].ImageURL.URL)
+	if err != nil || mimeType != "image/jpeg" {
+		t.Fatalf("expected JPEG data URL, got %s (%v)", mimeType, err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode downscaled image: %v", err)
+	}
+	if config.Width != 50 || config.Height != 25 {
+		t.Errorf("expected 50x25 image, got %dx%d", config.Width, config.Height)
+	}
+
+	// Images that already fit are left untouched
+	data, _, _ = decodeDataURL(parts[1].ImageURL.URL)
+	if !bytes.Equal(data, testPNG(t)) {
+		t.Error("expected small image to be unchanged")
+	}
+
+	_, err = NewMessage("user").
+		ImageBytes(testJPEG(t, 200, 200), "").
+		MaxImageBytes(100).
+		Build()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError for oversized image, got %v", err)
+	}
+}
+
+func TestMessageBuilderModalities(t *testing.T) {
+	textOnly := Model{ID: "text/model", Architecture: ModelArchitecture{InputModalities: []string{"text"}}}
+	vision := Model{ID: "vision/model", Architecture: ModelArchitecture{InputModalities: []string{"text", "image"}}}
+
+	builder := NewMessage("user").Text("Describe").ImageURL("https://example.com/a.png", "")
+
+	if _, err := builder.BuildFor(vision); err != nil {
+		t.Errorf("expected vision model to accept images, got %v", err)
+	}
+
+	_, err := builder.BuildFor(textOnly)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "image") {
+		t.Errorf("expected modality error, got %v", err)
+	}
+
+	audio := NewAudioPart([]byte("RIFF"), AudioFormatWAV)
+	if err := ValidateMessageModalities(CreateAudioMessage("user", "Transcribe", audio), vision); err == nil {
+		t.Error("expected audio to be rejected")
+	}
+	if err := ValidateMessageModalities(CreateUserMessage("plain"), textOnly); err != nil {
+		t.Errorf("expected string content to be accepted, got %v", err)
+	}
+	if err := ValidateMessageModalities(CreateAudioMessage("user", "Transcribe", audio), Model{}); err != nil {
+		t.Errorf("expected models without modalities not to be checked, got %v", err)
+	}
+}
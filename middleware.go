@@ -0,0 +1,233 @@
+package openrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// RoundTripRequest is the envelope passed through the middleware chain for every
+// API call made by the client, including streaming requests.
+type RoundTripRequest struct {
+	// Method is the HTTP method (GET, POST, PATCH, DELETE)
+	Method string
+	// Endpoint is the path relative to the base URL, including any query string (e.g. "/chat/completions")
+	Endpoint string
+	// Body is the request value that will be JSON encoded, such as *ChatCompletionRequest; nil for requests without a body
+	Body interface{}
+	// Header contains the headers that will be sent, including Authorization
+	Header http.Header
+	// Stream reports whether the request expects a Server-Sent Events response
+	Stream bool
+
+	// result is the value the response body is decoded into
+	result interface{}
+	// noClientTimeout sends the request without the HTTP client's timeout
+	noClientTimeout bool
+}
+
+// RoundTripResponse is the result of a RoundTripFunc.
+type RoundTripResponse struct {
+	// StatusCode is the HTTP status code
+	StatusCode int
+	// Header contains the response headers
+	Header http.Header
+	// Body is the raw response body; empty for streaming requests
+	Body []byte
+	// Result is the decoded response, such as *ChatCompletionResponse; nil for streaming requests
+	Result interface{}
+	// Stream is the Server-Sent Events body of a streaming request. Middleware may
+	// wrap it to observe events as they are read; it is closed by the stream.
+	Stream io.ReadCloser
+}
+
+// RoundTripFunc sends a request to the API and returns its response. Errors returned
+// for non-2xx responses are *RequestError values.
+type RoundTripFunc func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error)
+
+// Middleware wraps a RoundTripFunc to observe or modify requests and responses.
+// Middleware runs once per attempt, so retried requests pass through it again.
+//
+// Example:
+//
+//	timing := func(next openrouter.RoundTripFunc) openrouter.RoundTripFunc {
+//	    return func(ctx context.Context, req *openrouter.RoundTripRequest) (*openrouter.RoundTripResponse, error) {
+//	        start := time.Now()
+//	        resp, err := next(ctx, req)
+//	        log.Printf("%s %s took %v", req.Method, req.Endpoint, time.Since(start))
+//	        return resp, err
+//	    }
+//	}
+//
+//	client := openrouter.NewClient(openrouter.WithMiddleware(timing))
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// roundTrip sends the request through the middleware chain.
+func (c *Client) roundTrip(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+	next := RoundTripFunc(c.send)
+
+	// Logging wraps the HTTP call directly so it records what is actually sent
+	if c.logger != nil {
+		next = c.logRequests(next)
+	}
+
+	// The circuit breaker sees each attempt, after any middleware rewrites
+	if c.circuitBreaker != nil {
+		next = c.circuitBreaker.breakCircuits(next)
+	}
+
+	// The first registered middleware is the outermost
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		next = c.middleware[i](next)
+	}
+
+	return next(ctx, req)
+}
+
+// send performs the HTTP request described by req. It is the innermost RoundTripFunc.
+func (c *Client) send(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	var reqBody io.Reader
+	if req.Body != nil {
+		jsonData, err := json.Marshal(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, c.baseURL+req.Endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header = req.Header.Clone()
+
+	httpClient := c.httpClient
+	if req.noClientTimeout && httpClient.Timeout > 0 {
+		untimed := *httpClient
+		untimed.Timeout = 0
+		httpClient = &untimed
+	}
+
+	// Perform request
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	c.recordRateLimit(resp.Header)
+
+	if req.Stream {
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			return nil, newRequestError(resp, body)
+		}
+
+		return &RoundTripResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Stream:     resp.Body,
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode >= 400 {
+		return nil, newRequestError(resp, respBody)
+	}
+
+	result := &RoundTripResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}
+
+	// Unmarshal response
+	if req.result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, req.result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		result.Result = req.result
+	}
+
+	return result, nil
+}
+
+// requestHeaders builds the headers sent with every request.
+func (c *Client) requestHeaders(body interface{}, stream bool) http.Header {
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer "+c.apiKey)
+	header.Set("Content-Type", "application/json")
+
+	if stream {
+		header.Set("Accept", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+	}
+
+	if c.referer != "" {
+		header.Set("HTTP-Referer", c.referer)
+	}
+
+	if c.appName != "" {
+		header.Set("X-Title", c.appName)
+	}
+
+	// Add custom headers
+	for key, value := range c.customHeaders {
+		header.Set(key, value)
+	}
+
+	// Add metadata headers if present
+	if reqStruct, ok := body.(interface{ GetMetadata() map[string]interface{} }); ok {
+		for key, value := range reqStruct.GetMetadata() {
+			if strValue, ok := value.(string); ok {
+				header.Set("X-"+key, strValue)
+			}
+		}
+	}
+
+	return header
+}
+
+// copyResult stores the result of a round trip in v. Middleware may replace the
+// decoded result (e.g. when serving from a cache), so it is copied when it is not
+// v itself, falling back to decoding the raw body.
+func copyResult(resp *RoundTripResponse, v interface{}) error {
+	if v == nil || resp == nil || resp.Result == v {
+		return nil
+	}
+
+	if resp.Result != nil {
+		dst := reflect.ValueOf(v)
+		src := reflect.ValueOf(resp.Result)
+		if dst.Kind() == reflect.Ptr && src.Type() == dst.Type() && !src.IsNil() {
+			dst.Elem().Set(src.Elem())
+			return nil
+		}
+	}
+
+	if len(resp.Body) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(resp.Body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
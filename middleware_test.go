@@ -0,0 +1,221 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMiddlewareOrderAndHeaders(t *testing.T) {
+	var authHeader, traceHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		traceHeader = r.Header.Get("X-Trace")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse("hello"))
+	}))
+	defer server.Close()
+
+	var order []string
+	record := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+				order = append(order, name+" before")
+				resp, err := next(ctx, req)
+				order = append(order, name+" after")
+				return resp, err
+			}
+		}
+	}
+
+	var seen *RoundTripRequest
+	var decoded *ChatCompletionResponse
+	inspect := func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+			seen = req
+			req.Header.Set("Authorization", "Bearer refreshed-key")
+			req.Header.Set("X-Trace", "abc")
+			resp, err := next(ctx, req)
+			if err == nil {
+				decoded, _ = resp.Result.(*ChatCompletionResponse)
+			}
+			return resp, err
+		}
+	}
+
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithMiddleware(record("outer"), record("inner")),
+		WithMiddleware(inspect),
+	)
+
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedOrder := []string{"outer before", "inner before", "inner after", "outer after"}
+	if strings.Join(order, ",") != strings.Join(expectedOrder, ",") {
+		t.Errorf("expected order %v, got %v", expectedOrder, order)
+	}
+
+	if seen.Method != "POST" || seen.Endpoint != "/chat/completions" || seen.Stream {
+		t.Errorf("unexpected request envelope: %+v", seen)
+	}
+	if body, ok := seen.Body.(*ChatCompletionRequest); !ok || body.Model != "test-model" {
+		t.Errorf("expected typed request body, got %T", seen.Body)
+	}
+	if authHeader != "Bearer refreshed-key" || traceHeader != "abc" {
+		t.Errorf("expected middleware headers to be sent, got %q and %q", authHeader, traceHeader)
+	}
+	if decoded == nil || decoded.ID != resp.ID {
+		t.Errorf("expected middleware to see the decoded response, got %+v", decoded)
+	}
+}
+
+func TestMiddlewareSeesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"bad request"}}`))
+	}))
+	defer server.Close()
+
+	var seenErr error
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+				resp, err := next(ctx, req)
+				seenErr = err
+				return resp, err
+			}
+		}),
+	)
+
+	_, err := client.GetCredits(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if reqErr, ok := IsRequestError(seenErr); !ok || reqErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected middleware to see RequestError, got %v", seenErr)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	cached := &ChatCompletionResponse{ID: "cached"}
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+				return &RoundTripResponse{StatusCode: http.StatusOK, Result: cached}, nil
+			}
+		}),
+	)
+
+	resp, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.ID != "cached" {
+		t.Errorf("expected cached response, got %q", resp.ID)
+	}
+	if atomic.LoadInt32(&calls) != 0 {
+		t.Error("expected no request to reach the server")
+	}
+
+	// Raw bodies are decoded when no typed result is provided
+	client = NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+				return &RoundTripResponse{StatusCode: http.StatusOK, Body: []byte(`{"id":"raw"}`)}, nil
+			}
+		}),
+	)
+
+	resp, err = client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.ID != "raw" {
+		t.Errorf("expected response decoded from raw body, got %q", resp.ID)
+	}
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	bytes *int64
+}
+
+func (r countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddInt64(r.bytes, int64(n))
+	return n, err
+}
+
+func TestMiddlewareStreaming(t *testing.T) {
+	var accept, trace string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		trace = r.Header.Get("X-Trace")
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"id\":\"1\",\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	var streamed bool
+	var read int64
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(ctx context.Context, req *RoundTripRequest) (*RoundTripResponse, error) {
+				streamed = req.Stream
+				req.Header.Set("X-Trace", "abc")
+				resp, err := next(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+				resp.Stream = countingReadCloser{ReadCloser: resp.Stream, bytes: &read}
+				return resp, nil
+			}
+		}),
+	)
+
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var content string
+	for event := range stream.Events() {
+		content += ConcatenateChatStreamResponses([]ChatCompletionResponse{event})
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	if !streamed {
+		t.Error("expected request to be marked as streaming")
+	}
+	if accept != "text/event-stream" || trace != "abc" {
+		t.Errorf("unexpected headers: Accept=%q X-Trace=%q", accept, trace)
+	}
+	if content != "Hi" {
+		t.Errorf("unexpected content: %q", content)
+	}
+	if atomic.LoadInt64(&read) == 0 {
+		t.Error("expected wrapped stream body to be read")
+	}
+}
@@ -35,7 +35,22 @@ type ChatCompletionRequest struct {
 	Route             string                 `json:"route,omitempty"`
 	Plugins           []Plugin               `json:"plugins,omitempty"`
 	WebSearchOptions  *WebSearchOptions      `json:"web_search_options,omitempty"`
+	Usage             *UsageConfig           `json:"usage,omitempty"`
+	Reasoning         *ReasoningConfig       `json:"reasoning,omitempty"`
+	Modalities        []string               `json:"modalities,omitempty"`
+	Audio             *AudioOutputConfig     `json:"audio,omitempty"`
 	Metadata          map[string]interface{} `json:"-"` // Used for headers
+
+	// Client-side settings that are not sent to the API
+	validationRetries int
+	retryConfig       *RetryConfig
+	generationStats   bool
+	hedge             *HedgeConfig
+	streamTimeouts    *StreamTimeouts
+	streamReconnect   *StreamReconnectConfig
+
+	// optionErr is set by an option that could not be applied
+	optionErr error
 }
 
 // CompletionRequest represents a legacy completion request to the OpenRouter API.
@@ -68,7 +83,18 @@ type CompletionRequest struct {
 	Route             string                 `json:"route,omitempty"`
 	Plugins           []Plugin               `json:"plugins,omitempty"`
 	WebSearchOptions  *WebSearchOptions      `json:"web_search_options,omitempty"`
+	Usage             *UsageConfig           `json:"usage,omitempty"`
+	Reasoning         *ReasoningConfig       `json:"reasoning,omitempty"`
 	Metadata          map[string]interface{} `json:"-"` // Used for headers
+
+	// Client-side settings that are not sent to the API
+	retryConfig     *RetryConfig
+	generationStats bool
+	streamTimeouts  *StreamTimeouts
+	streamReconnect *StreamReconnectConfig
+
+	// optionErr is set by an option that could not be applied
+	optionErr error
 }
 
 // Message represents a message in the chat completion request.
@@ -79,16 +105,57 @@ type Message struct {
 	ToolCalls   []ToolCall     `json:"tool_calls,omitempty"`
 	ToolCallID  string         `json:"tool_call_id,omitempty"`
 	Annotations []Annotation   `json:"annotations,omitempty"`
+
+	// Reasoning is the model's reasoning text, if the model produced any and it was not excluded
+	Reasoning string `json:"reasoning,omitempty"`
+	// ReasoningDetails are the structured reasoning blocks. Send them back unmodified
+	// with the assistant message in multi-turn conversations so the model can continue
+	// its reasoning (required by some providers when using tools).
+	ReasoningDetails []ReasoningDetail `json:"reasoning_details,omitempty"`
+	// Audio is the audio output of the model, when requested with WithAudioOutput
+	Audio *MessageAudio `json:"audio,omitempty"`
+	// Images are the images generated by the model, when requested with ModalityImage
+	Images []MessageImage `json:"images,omitempty"`
 }
 
 // MessageContent can be either a string or an array of content parts.
 type MessageContent interface{}
 
-// ContentPart represents a part of message content (text or image).
+// ContentPart represents a part of message content (text, image, file or audio).
 type ContentPart struct {
 	Type     string    `json:"type"`
 	Text     string    `json:"text,omitempty"`
 	ImageURL *ImageURL `json:"image_url,omitempty"`
+	File     *File     `json:"file,omitempty"`
+	// InputAudio is the audio of an "input_audio" part
+	InputAudio *InputAudio `json:"input_audio,omitempty"`
+	// CacheControl marks the content up to and including this part for prompt caching
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+// File represents a file (e.g. a PDF) in the message content.
+type File struct {
+	// Filename is the name of the file, including its extension
+	Filename string `json:"filename"`
+	// FileData is the file as a base64 data URL, or a publicly accessible URL
+	FileData string `json:"file_data"`
+}
+
+// InputAudio represents audio in the message content.
+type InputAudio struct {
+	// Data is the base64 encoded audio
+	Data string `json:"data"`
+	// Format is the audio format ("wav" or "mp3")
+	Format AudioFormat `json:"format"`
+}
+
+// CacheControl marks a cache breakpoint for providers with explicit prompt caching
+// (e.g. Anthropic and Gemini).
+type CacheControl struct {
+	// Type is the cache type; "ephemeral" is the only supported type
+	Type string `json:"type"`
+	// TTL optionally sets the cache lifetime (e.g. "1h"); providers default to 5 minutes
+	TTL string `json:"ttl,omitempty"`
 }
 
 // ImageURL represents an image URL in the message content.
@@ -125,6 +192,8 @@ type Function struct {
 
 // ToolCall represents a tool call made by the model.
 type ToolCall struct {
+	// Index identifies the tool call within a streamed delta; it is only set on stream chunks
+	Index    *int         `json:"index,omitempty"`
 	ID       string       `json:"id"`
 	Type     string       `json:"type"`
 	Function FunctionCall `json:"function"`
@@ -173,19 +242,37 @@ type ChatCompletionResponse struct {
 	Object            string   `json:"object"`
 	Created           int64    `json:"created"`
 	Model             string   `json:"model"`
+	Provider          string   `json:"provider,omitempty"`
 	Choices           []Choice `json:"choices"`
 	Usage             Usage    `json:"usage"`
 	SystemFingerprint string   `json:"system_fingerprint,omitempty"`
+
+	// Generation holds the generation stats when requested with WithGenerationStats
+	Generation *GenerationData `json:"-"`
+	// GenerationErr is the error that occurred if the generation stats could not be fetched
+	GenerationErr error `json:"-"`
+	// Hedge reports the hedged requests when requested with WithHedging
+	Hedge *HedgeReport `json:"-"`
+	// Reconnect is set on the first stream chunk after a reconnection enabled with WithStreamReconnect
+	Reconnect *StreamReconnect `json:"-"`
 }
 
 // CompletionResponse represents a legacy completion response from the OpenRouter API.
 type CompletionResponse struct {
-	ID      string             `json:"id"`
-	Object  string             `json:"object"`
-	Created int64              `json:"created"`
-	Model   string             `json:"model"`
-	Choices []CompletionChoice `json:"choices"`
-	Usage   Usage              `json:"usage"`
+	ID       string             `json:"id"`
+	Object   string             `json:"object"`
+	Created  int64              `json:"created"`
+	Model    string             `json:"model"`
+	Provider string             `json:"provider,omitempty"`
+	Choices  []CompletionChoice `json:"choices"`
+	Usage    Usage              `json:"usage"`
+
+	// Generation holds the generation stats when requested with WithCompletionGenerationStats
+	Generation *GenerationData `json:"-"`
+	// GenerationErr is the error that occurred if the generation stats could not be fetched
+	GenerationErr error `json:"-"`
+	// Reconnect is set on the first stream chunk after a reconnection enabled with WithCompletionStreamReconnect
+	Reconnect *StreamReconnect `json:"-"`
 }
 
 // Choice represents a choice in the chat completion response.
@@ -201,15 +288,71 @@ type Choice struct {
 type CompletionChoice struct {
 	Index        int       `json:"index"`
 	Text         string    `json:"text"`
+	Reasoning    string    `json:"reasoning,omitempty"`
 	FinishReason string    `json:"finish_reason"`
 	LogProbs     *LogProbs `json:"logprobs,omitempty"`
 }
 
+// UsageConfig controls usage accounting for a request.
+type UsageConfig struct {
+	Include bool `json:"include"`
+}
+
 // Usage represents token usage information.
+// Cost and the detail fields are only set when usage accounting is enabled
+// with WithUsageAccounting.
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens            int                      `json:"prompt_tokens"`
+	CompletionTokens        int                      `json:"completion_tokens"`
+	TotalTokens             int                      `json:"total_tokens"`
+	Cost                    float64                  `json:"cost,omitempty"`    // Credits charged for the request
+	IsBYOK                  bool                     `json:"is_byok,omitempty"` // Whether the request used your own provider key
+	PromptTokensDetails     *PromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+	CostDetails             *CostDetails             `json:"cost_details,omitempty"`
+}
+
+// PromptTokensDetails breaks down the prompt tokens of a request.
+type PromptTokensDetails struct {
+	CachedTokens     int `json:"cached_tokens"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+	AudioTokens      int `json:"audio_tokens,omitempty"`
+}
+
+// CompletionTokensDetails breaks down the completion tokens of a request.
+type CompletionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+	ImageTokens     int `json:"image_tokens,omitempty"`
+}
+
+// CostDetails breaks down the cost of a request.
+type CostDetails struct {
+	// UpstreamInferenceCost is what the upstream provider charged, for BYOK requests
+	UpstreamInferenceCost float64 `json:"upstream_inference_cost"`
+}
+
+// CachedTokens returns the number of prompt tokens read from the cache.
+func (u Usage) CachedTokens() int {
+	if u.PromptTokensDetails == nil {
+		return 0
+	}
+	return u.PromptTokensDetails.CachedTokens
+}
+
+// CacheWriteTokens returns the number of prompt tokens written to the cache.
+func (u Usage) CacheWriteTokens() int {
+	if u.PromptTokensDetails == nil {
+		return 0
+	}
+	return u.PromptTokensDetails.CacheWriteTokens
+}
+
+// ReasoningTokens returns the number of completion tokens used for reasoning.
+func (u Usage) ReasoningTokens() int {
+	if u.CompletionTokensDetails == nil {
+		return 0
+	}
+	return u.CompletionTokensDetails.ReasoningTokens
 }
 
 // LogProbs represents log probability information.
@@ -250,6 +393,11 @@ type StreamEvent struct {
 	Event string
 	Data  string
 	Retry *time.Duration
+	// Reconnect is set on the first event after the stream reconnected
+	Reconnect *StreamReconnect
+
+	// received is when the event was read from the connection
+	received time.Time
 }
 
 // ErrorResponse represents an error response from the OpenRouter API.
@@ -265,6 +413,39 @@ type APIError struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// ReasoningConfig configures reasoning tokens for models that support them.
+// Effort and MaxTokens are alternatives; providers use whichever they support.
+type ReasoningConfig struct {
+	// Effort sets the reasoning effort ("high", "medium", "low" or "minimal")
+	Effort ReasoningEffort `json:"effort,omitempty"`
+	// MaxTokens sets a token budget for reasoning
+	MaxTokens *int `json:"max_tokens,omitempty"`
+	// Exclude uses reasoning internally but leaves it out of the response
+	Exclude *bool `json:"exclude,omitempty"`
+	// Enabled turns reasoning on with the default settings, or off
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// ReasoningDetail is a structured reasoning block returned by the model.
+type ReasoningDetail struct {
+	// Type is the block type ("reasoning.text", "reasoning.summary" or "reasoning.encrypted")
+	Type string `json:"type"`
+	// ID identifies the block, if the provider assigns one
+	ID string `json:"id,omitempty"`
+	// Format identifies the provider format of the block (e.g. "anthropic-claude-v1")
+	Format string `json:"format,omitempty"`
+	// Index identifies the block within a streamed response
+	Index *int `json:"index,omitempty"`
+	// Text is the reasoning text of a "reasoning.text" block
+	Text string `json:"text,omitempty"`
+	// Signature verifies the text of a "reasoning.text" block
+	Signature string `json:"signature,omitempty"`
+	// Summary is the reasoning summary of a "reasoning.summary" block
+	Summary string `json:"summary,omitempty"`
+	// Data is the encrypted reasoning of a "reasoning.encrypted" block
+	Data string `json:"data,omitempty"`
+}
+
 // Plugin represents a plugin configuration for enhancing model responses.
 type Plugin struct {
 	// ID is the plugin identifier (e.g., "web" for web search)
@@ -275,6 +456,14 @@ type Plugin struct {
 	MaxResults int `json:"max_results,omitempty"`
 	// SearchPrompt customizes the prompt used to attach search results
 	SearchPrompt string `json:"search_prompt,omitempty"`
+	// PDF configures the file-parser plugin
+	PDF *PDFParserConfig `json:"pdf,omitempty"`
+}
+
+// PDFParserConfig configures how the file-parser plugin parses PDFs.
+type PDFParserConfig struct {
+	// Engine selects the parsing engine ("pdf-text", "mistral-ocr" or "native")
+	Engine string `json:"engine,omitempty"`
 }
 
 // WebSearchOptions configures native web search behavior for supported models.
@@ -289,6 +478,19 @@ type Annotation struct {
 	Type string `json:"type"`
 	// URLCitation contains details for URL citation annotations
 	URLCitation *URLCitation `json:"url_citation,omitempty"`
+	// File contains the parsed content of a file sent with the request. Sending the
+	// annotation back with the assistant message skips parsing the file again.
+	File *FileAnnotation `json:"file,omitempty"`
+}
+
+// FileAnnotation represents the parsed content of a file in a message annotation.
+type FileAnnotation struct {
+	// Hash identifies the file contents
+	Hash string `json:"hash"`
+	// Name is the filename
+	Name string `json:"name,omitempty"`
+	// Content is the parsed content of the file
+	Content []ContentPart `json:"content,omitempty"`
 }
 
 // URLCitation represents a URL citation in a message annotation.
@@ -327,6 +529,18 @@ type Model struct {
 	Pricing             ModelPricing            `json:"pricing"`
 }
 
+// Modalities used in ModelArchitecture and WithModalities.
+const (
+	// ModalityText is text input or output
+	ModalityText = "text"
+	// ModalityImage is image input or output
+	ModalityImage = "image"
+	// ModalityAudio is audio input or output
+	ModalityAudio = "audio"
+	// ModalityFile is file input, such as PDFs
+	ModalityFile = "file"
+)
+
 // ModelArchitecture contains information about a model's architecture.
 type ModelArchitecture struct {
 	InputModalities  []string `json:"input_modalities"`
@@ -405,10 +619,12 @@ type ModelEndpoint struct {
 
 // ModelEndpointPricing contains pricing information for a specific endpoint.
 type ModelEndpointPricing struct {
-	Request    string `json:"request"`
-	Image      string `json:"image"`
-	Prompt     string `json:"prompt"`
-	Completion string `json:"completion"`
+	Request         string  `json:"request"`
+	Image           string  `json:"image"`
+	Prompt          string  `json:"prompt"`
+	Completion      string  `json:"completion"`
+	InputCacheRead  *string `json:"input_cache_read,omitempty"`
+	InputCacheWrite *string `json:"input_cache_write,omitempty"`
 }
 
 // ProvidersResponse represents the response from the list providers endpoint.
@@ -456,6 +672,45 @@ type ActivityData struct {
 	ReasoningTokens    float64 `json:"reasoning_tokens"`
 }
 
+// GenerationResponse represents the response from the generation stats endpoint.
+type GenerationResponse struct {
+	Data GenerationData `json:"data"`
+}
+
+// GenerationData contains the stats of a single generation, including its billed cost.
+type GenerationData struct {
+	ID                     string   `json:"id"`
+	UpstreamID             string   `json:"upstream_id,omitempty"`
+	TotalCost              float64  `json:"total_cost"`
+	CacheDiscount          *float64 `json:"cache_discount"`
+	UpstreamInferenceCost  *float64 `json:"upstream_inference_cost"`
+	Usage                  float64  `json:"usage"`
+	IsBYOK                 bool     `json:"is_byok"`
+	CreatedAt              string   `json:"created_at"`
+	Model                  string   `json:"model"`
+	AppID                  *int     `json:"app_id"`
+	Streamed               bool     `json:"streamed"`
+	Cancelled              bool     `json:"cancelled"`
+	ProviderName           string   `json:"provider_name"`
+	APIType                string   `json:"api_type,omitempty"`
+	Latency                *float64 `json:"latency"`            // Total latency in milliseconds
+	ModerationLatency      *float64 `json:"moderation_latency"` // Moderation latency in milliseconds
+	GenerationTime         *float64 `json:"generation_time"`    // Generation time in milliseconds
+	FinishReason           string   `json:"finish_reason"`
+	NativeFinishReason     string   `json:"native_finish_reason"`
+	TokensPrompt           int      `json:"tokens_prompt"`
+	TokensCompletion       int      `json:"tokens_completion"`
+	NativeTokensPrompt     *int     `json:"native_tokens_prompt"`
+	NativeTokensCompletion *int     `json:"native_tokens_completion"`
+	NativeTokensReasoning  *int     `json:"native_tokens_reasoning"`
+	NativeTokensCached     *int     `json:"native_tokens_cached"`
+	NumMediaPrompt         *int     `json:"num_media_prompt"`
+	NumMediaCompletion     *int     `json:"num_media_completion"`
+	NumSearchResults       *int     `json:"num_search_results"`
+	Origin                 string   `json:"origin"`
+	ExternalUser           string   `json:"external_user,omitempty"`
+}
+
 // KeyResponse represents the response from the get current API key endpoint.
 type KeyResponse struct {
 	Data KeyData `json:"data"`
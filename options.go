@@ -71,6 +71,51 @@ func WithRetry(maxRetries int, retryDelay time.Duration) ClientOption {
 	}
 }
 
+// WithRetryConfig sets the full retry configuration for the client, including
+// backoff shape, the retryable error predicate and the OnRetry callback.
+// It takes precedence over WithRetry. Unset InitialDelay, MaxDelay, Multiplier
+// and RetryableError fields fall back to the client defaults; InitialDelay
+// defaults to the delay set with WithRetry.
+func WithRetryConfig(config *RetryConfig) ClientOption {
+	return func(c *Client) {
+		c.retryConfig = config
+	}
+}
+
+// WithRateLimiter throttles all requests made by the client, including streams,
+// with the given limiter. A single limiter can be shared between clients.
+func WithRateLimiter(limiter Limiter) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithTokenRateLimiter budgets prompt and completion tokens across all requests
+// made by the client. Requests wait while the budget is exhausted, and the usage
+// reported by each response is deducted from it.
+func WithTokenRateLimiter(limiter *TokenRateLimiter) ClientOption {
+	return func(c *Client) {
+		c.tokenLimiter = limiter
+	}
+}
+
+// WithMiddleware adds middleware that wraps every request made by the client,
+// including streaming requests. Middleware runs in the order given, with the
+// first one outermost; repeated calls append to the chain.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// WithInstrumentation reports every API call made by the client to the given
+// instrumentation, e.g. one created by the otel subpackage.
+func WithInstrumentation(instrumentation Instrumentation) ClientOption {
+	return func(c *Client) {
+		c.instrumentation = instrumentation
+	}
+}
+
 // WithHeader adds a custom header to all requests.
 func WithHeader(key, value string) ClientOption {
 	return func(c *Client) {
@@ -233,6 +278,107 @@ func WithMetadata(metadata map[string]interface{}) ChatCompletionOption {
 	}
 }
 
+// WithRetryOverride overrides the client's retry configuration for this request only.
+// Use &RetryConfig{MaxRetries: 0} to disable retries for latency-sensitive calls.
+func WithRetryOverride(config *RetryConfig) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		setRetryConfig(r, config)
+	}
+}
+
+// WithGenerationStats fetches the generation stats, including the billed cost, with
+// GetGeneration after the completion finishes and attaches them to the response's
+// Generation field. Stats become available with a short delay, so this adds latency.
+// If the stats cannot be fetched, the request still succeeds and the error is set in
+// the response's GenerationErr field. Streams ignore this option; call GetGeneration
+// with the stream's ID.
+func WithGenerationStats() ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		setGenerationStats(r, true)
+	}
+}
+
+// WithStreamTimeouts limits how long ChatCompleteStream may wait for the first event,
+// between data and in total. A stream that times out ends with a *StreamError whose
+// cause is ErrStreamFirstTokenTimeout, ErrStreamIdleTimeout or ErrStreamTotalTimeout.
+// Other requests ignore this option.
+//
+// Example:
+//
+//	stream, err := client.ChatCompleteStream(ctx, messages,
+//	    openrouter.WithStreamTimeouts(openrouter.StreamTimeouts{
+//	        FirstToken: 20 * time.Second,
+//	        Idle:       10 * time.Second,
+//	        Total:      5 * time.Minute,
+//	    }),
+//	)
+func WithStreamTimeouts(timeouts StreamTimeouts) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		setStreamTimeouts(r, timeouts)
+	}
+}
+
+// WithUsageAccounting asks the API to include the request's cost and a detailed
+// token breakdown (cached and reasoning tokens) in the response usage. For streams,
+// the usage arrives in the final chunk and is available from the stream's Usage method.
+func WithUsageAccounting() ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		setUsageAccounting(r)
+	}
+}
+
+// WithReasoning sets the full reasoning configuration for the request.
+func WithReasoning(config ReasoningConfig) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		r.Reasoning = &config
+	}
+}
+
+// WithReasoningEffort enables reasoning with the given effort level.
+func WithReasoningEffort(effort ReasoningEffort) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		ensureReasoning(r).Effort = effort
+	}
+}
+
+// WithReasoningMaxTokens enables reasoning with a token budget.
+func WithReasoningMaxTokens(maxTokens int) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		ensureReasoning(r).MaxTokens = &maxTokens
+	}
+}
+
+// WithReasoningExcluded lets the model reason but leaves the reasoning out of the
+// response. Reasoning tokens are still billed.
+func WithReasoningExcluded() ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		exclude := true
+		ensureReasoning(r).Exclude = &exclude
+	}
+}
+
+// WithModalities sets the output modalities of the request (e.g. ModalityText and ModalityAudio).
+func WithModalities(modalities ...string) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		r.Modalities = modalities
+	}
+}
+
+// WithAudioOutput requests audio output with the given voice and format, in addition
+// to text. Streamed audio must use AudioFormatPCM16.
+func WithAudioOutput(voice string, format AudioFormat) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		r.Modalities = []string{ModalityText, ModalityAudio}
+		r.Audio = &AudioOutputConfig{Voice: voice, Format: format}
+	}
+}
+
+// WithImageOutput requests generated images in addition to text, for models with
+// image output. The images are returned in Message.Images.
+func WithImageOutput() ChatCompletionOption {
+	return WithModalities(ModalityImage, ModalityText)
+}
+
 // CompletionOption is a functional option for completion requests.
 type CompletionOption func(*CompletionRequest)
 
@@ -368,6 +514,61 @@ func setWebSearchOptions[T RequestConfig](r T, options *WebSearchOptions) {
 	}
 }
 
+// setRetryConfig is a generic helper to set the per-request retry configuration.
+func setRetryConfig[T RequestConfig](r T, config *RetryConfig) {
+	switch req := any(r).(type) {
+	case *ChatCompletionRequest:
+		req.retryConfig = config
+	case *CompletionRequest:
+		req.retryConfig = config
+	}
+}
+
+// setUsageAccounting is a generic helper to enable usage accounting.
+func setUsageAccounting[T RequestConfig](r T) {
+	switch req := any(r).(type) {
+	case *ChatCompletionRequest:
+		req.Usage = &UsageConfig{Include: true}
+	case *CompletionRequest:
+		req.Usage = &UsageConfig{Include: true}
+	}
+}
+
+// setStreamTimeouts is a generic helper to set the stream timeouts.
+func setStreamTimeouts[T RequestConfig](r T, timeouts StreamTimeouts) {
+	switch req := any(r).(type) {
+	case *ChatCompletionRequest:
+		req.streamTimeouts = &timeouts
+	case *CompletionRequest:
+		req.streamTimeouts = &timeouts
+	}
+}
+
+// setOptionError is a generic helper to record an option that could not be applied.
+// The request fails with the first such error.
+func setOptionError[T RequestConfig](r T, err error) {
+	switch req := any(r).(type) {
+	case *ChatCompletionRequest:
+		if req.optionErr == nil {
+			req.optionErr = err
+		}
+	case *CompletionRequest:
+		if req.optionErr == nil {
+			req.optionErr = err
+		}
+	}
+}
+
+// setGenerationStats is a generic helper to enable fetching generation stats.
+func setGenerationStats[T RequestConfig](r T, enabled bool) {
+	switch req := any(r).(type) {
+	case *ChatCompletionRequest:
+		req.generationStats = enabled
+	case *CompletionRequest:
+		req.generationStats = enabled
+	}
+}
+
 // ensureProvider is a generic helper to ensure provider is initialized.
 func ensureProvider[T RequestConfig](r T) *Provider {
 	switch req := any(r).(type) {
@@ -385,6 +586,23 @@ func ensureProvider[T RequestConfig](r T) *Provider {
 	return nil
 }
 
+// ensureReasoning is a generic helper to ensure the reasoning config is initialized.
+func ensureReasoning[T RequestConfig](r T) *ReasoningConfig {
+	switch req := any(r).(type) {
+	case *ChatCompletionRequest:
+		if req.Reasoning == nil {
+			req.Reasoning = &ReasoningConfig{}
+		}
+		return req.Reasoning
+	case *CompletionRequest:
+		if req.Reasoning == nil {
+			req.Reasoning = &ReasoningConfig{}
+		}
+		return req.Reasoning
+	}
+	return nil
+}
+
 // WithCompletionModel sets the model for the completion request.
 func WithCompletionModel(model string) CompletionOption {
 	return func(r *CompletionRequest) {
@@ -498,6 +716,68 @@ func WithZDR(enabled bool) ChatCompletionOption {
 	}
 }
 
+// WithCompletionRetryOverride overrides the client's retry configuration for this completion request only.
+func WithCompletionRetryOverride(config *RetryConfig) CompletionOption {
+	return func(r *CompletionRequest) {
+		setRetryConfig(r, config)
+	}
+}
+
+// WithCompletionUsageAccounting asks the API to include the request's cost and a
+// detailed token breakdown in the completion response usage.
+func WithCompletionUsageAccounting() CompletionOption {
+	return func(r *CompletionRequest) {
+		setUsageAccounting(r)
+	}
+}
+
+// WithCompletionReasoning sets the full reasoning configuration for the completion request.
+func WithCompletionReasoning(config ReasoningConfig) CompletionOption {
+	return func(r *CompletionRequest) {
+		r.Reasoning = &config
+	}
+}
+
+// WithCompletionReasoningEffort enables reasoning with the given effort level.
+func WithCompletionReasoningEffort(effort ReasoningEffort) CompletionOption {
+	return func(r *CompletionRequest) {
+		ensureReasoning(r).Effort = effort
+	}
+}
+
+// WithCompletionReasoningMaxTokens enables reasoning with a token budget.
+func WithCompletionReasoningMaxTokens(maxTokens int) CompletionOption {
+	return func(r *CompletionRequest) {
+		ensureReasoning(r).MaxTokens = &maxTokens
+	}
+}
+
+// WithCompletionReasoningExcluded lets the model reason but leaves the reasoning
+// out of the response.
+func WithCompletionReasoningExcluded() CompletionOption {
+	return func(r *CompletionRequest) {
+		exclude := true
+		ensureReasoning(r).Exclude = &exclude
+	}
+}
+
+// WithCompletionStreamTimeouts limits how long CompleteStream may wait for the first
+// event, between data and in total. See WithStreamTimeouts.
+func WithCompletionStreamTimeouts(timeouts StreamTimeouts) CompletionOption {
+	return func(r *CompletionRequest) {
+		setStreamTimeouts(r, timeouts)
+	}
+}
+
+// WithCompletionGenerationStats fetches the generation stats after the completion
+// finishes and attaches them to the response's Generation field. If the stats cannot
+// be fetched, the error is set in the response's GenerationErr field.
+func WithCompletionGenerationStats() CompletionOption {
+	return func(r *CompletionRequest) {
+		setGenerationStats(r, true)
+	}
+}
+
 // WithCompletionZDR enables Zero Data Retention for the completion request.
 // This ensures the request is only routed to endpoints with Zero Data Retention policy.
 func WithCompletionZDR(enabled bool) CompletionOption {
@@ -694,3 +694,47 @@ func TestTopKAndRepetitionPenalty(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestReasoningOptions(t *testing.T) {
+	req := &ChatCompletionRequest{}
+	WithReasoningEffort(ReasoningEffortHigh)(req)
+	WithReasoningExcluded()(req)
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	var body map[string]interface{}
+	json.Unmarshal(data, &body)
+
+	reasoning, ok := body["reasoning"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected reasoning object, got %s", data)
+	}
+	if reasoning["effort"] != "high" || reasoning["exclude"] != true {
+		t.Errorf("unexpected reasoning config: %v", reasoning)
+	}
+	if _, ok := reasoning["max_tokens"]; ok {
+		t.Error("expected max_tokens to be omitted")
+	}
+
+	budget := &ChatCompletionRequest{}
+	WithReasoningMaxTokens(2000)(budget)
+	if budget.Reasoning == nil || budget.Reasoning.MaxTokens == nil || *budget.Reasoning.MaxTokens != 2000 {
+		t.Errorf("unexpected reasoning config: %+v", budget.Reasoning)
+	}
+
+	enabled := true
+	full := &ChatCompletionRequest{}
+	WithReasoning(ReasoningConfig{Enabled: &enabled})(full)
+	if full.Reasoning == nil || full.Reasoning.Enabled == nil || !*full.Reasoning.Enabled {
+		t.Errorf("unexpected reasoning config: %+v", full.Reasoning)
+	}
+
+	completion := &CompletionRequest{}
+	WithCompletionReasoningEffort(ReasoningEffortLow)(completion)
+	WithCompletionReasoningMaxTokens(500)(completion)
+	if completion.Reasoning == nil || completion.Reasoning.Effort != ReasoningEffortLow || *completion.Reasoning.MaxTokens != 500 {
+		t.Errorf("unexpected completion reasoning config: %+v", completion.Reasoning)
+	}
+}
@@ -0,0 +1,24 @@
+module github.com/hra42/openrouter-go/otel
+
+go 1.25.1
+
+require (
+	github.com/hra42/openrouter-go v0.0.0
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/metric v1.38.0
+	go.opentelemetry.io/otel/sdk v1.38.0
+	go.opentelemetry.io/otel/sdk/metric v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+)
+
+// Unreleased: the otel module builds against the core module in this repository
+// until a core release with the Instrumentation API is tagged and required here.
+replace github.com/hra42/openrouter-go => ../
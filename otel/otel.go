@@ -0,0 +1,440 @@
+// Package otel instruments an openrouter.Client with OpenTelemetry tracing and
+// metrics following the GenAI semantic conventions.
+//
+// It is a separate module so that the core openrouter package keeps no external
+// dependencies. The instrumentation hooks into the client through
+// openrouter.WithInstrumentation:
+//
+//	client := openrouter.NewClient(
+//	    openrouter.WithAPIKey(apiKey),
+//	    openrouter.WithInstrumentation(otel.New()),
+//	)
+//
+// Every API call gets a client span. Chat and completion spans are named
+// "{operation} {model}" (e.g. "chat openai/gpt-4o") and carry gen_ai.* attributes
+// for the requested and response model, token usage and finish reasons; the
+// upstream provider chosen by OpenRouter is recorded as openrouter.provider.
+// Management endpoints get spans named after the HTTP method and path.
+// Retries are added as span events, and streams record the time to the first
+// token as both an event and a metric.
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	openrouter "github.com/hra42/openrouter-go"
+	gootel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name used for tracers and meters.
+const ScopeName = "github.com/hra42/openrouter-go/otel"
+
+// Attribute keys that are not part of the GenAI semantic conventions.
+const (
+	// ProviderKey is the upstream provider that served the request (e.g. "OpenAI")
+	ProviderKey = attribute.Key("openrouter.provider")
+	// RetryAttemptKey is the attempt number recorded on retry events
+	RetryAttemptKey = attribute.Key("openrouter.retry.attempt")
+	// RetryDelayKey is the delay in seconds before a retry
+	RetryDelayKey = attribute.Key("openrouter.retry.delay")
+)
+
+// Metric names recorded by the instrumentation.
+const (
+	OperationDurationMetric = "gen_ai.client.operation.duration"
+	TokenUsageMetric        = "gen_ai.client.token.usage"
+	TimeToFirstTokenMetric  = "openrouter.client.time_to_first_token"
+)
+
+// providerName is the gen_ai.provider.name reported for all calls.
+const providerName = "openrouter"
+
+// Bucket boundaries recommended by the GenAI semantic conventions.
+var (
+	durationBuckets = []float64{0.01, 0.02, 0.04, 0.08, 0.16, 0.32, 0.64, 1.28, 2.56, 5.12, 10.24, 20.48, 40.96, 81.92}
+	tokenBuckets    = []float64{1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864}
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures the instrumentation.
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider. Defaults to the global provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider. Defaults to the global provider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// Instrumentation implements openrouter.Instrumentation with OpenTelemetry.
+// It is safe for concurrent use and can be shared between clients.
+type Instrumentation struct {
+	tracer           trace.Tracer
+	duration         metric.Float64Histogram
+	tokenUsage       metric.Int64Histogram
+	timeToFirstToken metric.Float64Histogram
+}
+
+var _ openrouter.Instrumentation = (*Instrumentation)(nil)
+
+// New creates the instrumentation. Pass it to openrouter.WithInstrumentation.
+func New(opts ...Option) *Instrumentation {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = gootel.GetTracerProvider()
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = gootel.GetMeterProvider()
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+
+	// Instrument errors are reported to the global handler; the returned
+	// instruments are still usable no-ops in that case.
+	duration, err := meter.Float64Histogram(OperationDurationMetric,
+		metric.WithDescription("GenAI operation duration"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		gootel.Handle(err)
+	}
+
+	tokenUsage, err := meter.Int64Histogram(TokenUsageMetric,
+		metric.WithDescription("Measures number of input and output tokens used"),
+		metric.WithUnit("{token}"),
+		metric.WithExplicitBucketBoundaries(tokenBuckets...),
+	)
+	if err != nil {
+		gootel.Handle(err)
+	}
+
+	timeToFirstToken, err := meter.Float64Histogram(TimeToFirstTokenMetric,
+		metric.WithDescription("Time from sending a streaming request to receiving the first token"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		gootel.Handle(err)
+	}
+
+	return &Instrumentation{
+		tracer:           cfg.tracerProvider.Tracer(ScopeName),
+		duration:         duration,
+		tokenUsage:       tokenUsage,
+		timeToFirstToken: timeToFirstToken,
+	}
+}
+
+// StartOperation starts a span for an API call.
+func (i *Instrumentation) StartOperation(ctx context.Context, op openrouter.Operation) (context.Context, openrouter.OperationObserver) {
+	path := op.Endpoint
+	if u, err := url.Parse(op.Endpoint); err == nil {
+		path = u.Path
+	}
+
+	operation := operationName(path)
+	model, requestAttrs := requestAttributes(op.Request)
+
+	spanName := op.Method + " " + path
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(op.Method),
+		semconv.URLPathKey.String(path),
+	}
+
+	if operation != "" {
+		spanName = operation
+		if model != "" {
+			spanName += " " + model
+		}
+		attrs = append(attrs,
+			semconv.GenAIOperationNameKey.String(operation),
+			semconv.GenAIProviderNameKey.String(providerName),
+		)
+		attrs = append(attrs, requestAttrs...)
+	}
+
+	ctx, span := i.tracer.Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx, &observer{
+		instrumentation: i,
+		ctx:             ctx,
+		span:            span,
+		operation:       operation,
+		requestModel:    model,
+		start:           time.Now(),
+		finishReasons:   make(map[int]string),
+	}
+}
+
+// operationName maps an endpoint path to a gen_ai.operation.name, or "" for
+// endpoints that are not GenAI operations.
+func operationName(path string) string {
+	switch path {
+	case "/chat/completions":
+		return "chat"
+	case "/completions":
+		return "text_completion"
+	}
+	return ""
+}
+
+// requestAttributes returns the requested model and gen_ai.request.* attributes.
+func requestAttributes(request interface{}) (string, []attribute.KeyValue) {
+	var (
+		model       string
+		temperature *float64
+		topP        *float64
+		maxTokens   *int
+	)
+
+	switch r := request.(type) {
+	case *openrouter.ChatCompletionRequest:
+		model, temperature, topP, maxTokens = r.Model, r.Temperature, r.TopP, r.MaxTokens
+	case *openrouter.CompletionRequest:
+		model, temperature, topP, maxTokens = r.Model, r.Temperature, r.TopP, r.MaxTokens
+	default:
+		return "", nil
+	}
+
+	attrs := []attribute.KeyValue{semconv.GenAIRequestModel(model)}
+	if temperature != nil {
+		attrs = append(attrs, semconv.GenAIRequestTemperature(*temperature))
+	}
+	if topP != nil {
+		attrs = append(attrs, semconv.GenAIRequestTopP(*topP))
+	}
+	if maxTokens != nil {
+		attrs = append(attrs, semconv.GenAIRequestMaxTokens(*maxTokens))
+	}
+
+	return model, attrs
+}
+
+// observer records the events of a single API call on its span.
+type observer struct {
+	instrumentation *Instrumentation
+	ctx             context.Context
+	span            trace.Span
+	operation       string
+	requestModel    string
+	start           time.Time
+
+	mu            sync.Mutex
+	firstToken    bool
+	responseID    string
+	responseModel string
+	provider      string
+	usage         openrouter.Usage
+	finishReasons map[int]string
+}
+
+// Retry adds a retry event to the span.
+func (o *observer) Retry(attempt int, err error, delay time.Duration) {
+	o.span.AddEvent("retry", trace.WithAttributes(
+		RetryAttemptKey.Int(attempt),
+		RetryDelayKey.Float64(delay.Seconds()),
+		semconv.ErrorTypeKey.String(errorType(err)),
+		semconv.ExceptionMessage(err.Error()),
+	))
+}
+
+// StreamChunk collects response details from a stream chunk and records the
+// time to the first token, measured to when the chunk arrived.
+func (o *observer) StreamChunk(chunk interface{}, received time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	hasToken := false
+
+	switch c := chunk.(type) {
+	case openrouter.ChatCompletionResponse:
+		o.merge(c.ID, c.Model, c.Provider, c.Usage)
+		for _, choice := range c.Choices {
+			if choice.FinishReason != "" {
+				o.finishReasons[choice.Index] = choice.FinishReason
+			}
+			if delta := choice.Delta; delta != nil {
+				if content, ok := delta.Content.(string); (ok && content != "") || len(delta.ToolCalls) > 0 {
+					hasToken = true
+				}
+			}
+		}
+	case openrouter.CompletionResponse:
+		o.merge(c.ID, c.Model, c.Provider, c.Usage)
+		for _, choice := range c.Choices {
+			if choice.FinishReason != "" {
+				o.finishReasons[choice.Index] = choice.FinishReason
+			}
+			if choice.Text != "" {
+				hasToken = true
+			}
+		}
+	}
+
+	if hasToken && !o.firstToken {
+		o.firstToken = true
+		elapsed := received.Sub(o.start)
+		o.span.AddEvent("first_token", trace.WithTimestamp(received))
+		o.instrumentation.timeToFirstToken.Record(o.ctx, elapsed.Seconds(),
+			metric.WithAttributes(o.metricAttributes(nil)...))
+	}
+}
+
+// End records the response details and metrics, then ends the span.
+func (o *observer) End(response interface{}, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch r := response.(type) {
+	case *openrouter.ChatCompletionResponse:
+		o.merge(r.ID, r.Model, r.Provider, r.Usage)
+		for _, choice := range r.Choices {
+			o.finishReasons[choice.Index] = choice.FinishReason
+		}
+	case *openrouter.CompletionResponse:
+		o.merge(r.ID, r.Model, r.Provider, r.Usage)
+		for _, choice := range r.Choices {
+			o.finishReasons[choice.Index] = choice.FinishReason
+		}
+	}
+
+	if o.operation != "" {
+		o.span.SetAttributes(o.responseAttributes()...)
+	}
+
+	if err != nil {
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+		o.span.SetAttributes(semconv.ErrorTypeKey.String(errorType(err)))
+	}
+
+	if o.operation != "" {
+		o.instrumentation.duration.Record(o.ctx, time.Since(o.start).Seconds(),
+			metric.WithAttributes(o.metricAttributes(err)...))
+
+		if err == nil && o.usage != (openrouter.Usage{}) {
+			o.instrumentation.tokenUsage.Record(o.ctx, int64(o.usage.PromptTokens),
+				metric.WithAttributes(append(o.metricAttributes(nil), semconv.GenAITokenTypeInput)...))
+			o.instrumentation.tokenUsage.Record(o.ctx, int64(o.usage.CompletionTokens),
+				metric.WithAttributes(append(o.metricAttributes(nil), semconv.GenAITokenTypeOutput)...))
+		}
+	}
+
+	o.span.End()
+}
+
+// merge keeps the latest non-empty response details. The caller must hold mu.
+func (o *observer) merge(id, model, provider string, usage openrouter.Usage) {
+	if id != "" {
+		o.responseID = id
+	}
+	if model != "" {
+		o.responseModel = model
+	}
+	if provider != "" {
+		o.provider = provider
+	}
+	if usage != (openrouter.Usage{}) {
+		o.usage = usage
+	}
+}
+
+// responseAttributes returns the gen_ai.response.* and usage span attributes.
+func (o *observer) responseAttributes() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	if o.responseID != "" {
+		attrs = append(attrs, semconv.GenAIResponseID(o.responseID))
+	}
+	if o.responseModel != "" {
+		attrs = append(attrs, semconv.GenAIResponseModel(o.responseModel))
+	}
+	if o.provider != "" {
+		attrs = append(attrs, ProviderKey.String(o.provider))
+	}
+
+	if len(o.finishReasons) > 0 {
+		indexes := make([]int, 0, len(o.finishReasons))
+		for index := range o.finishReasons {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+
+		reasons := make([]string, 0, len(indexes))
+		for _, index := range indexes {
+			reasons = append(reasons, o.finishReasons[index])
+		}
+		attrs = append(attrs, semconv.GenAIResponseFinishReasons(reasons...))
+	}
+
+	if o.usage != (openrouter.Usage{}) {
+		attrs = append(attrs,
+			semconv.GenAIUsageInputTokens(o.usage.PromptTokens),
+			semconv.GenAIUsageOutputTokens(o.usage.CompletionTokens),
+		)
+	}
+
+	return attrs
+}
+
+// metricAttributes returns the attributes shared by all metrics of the call.
+// The caller must hold mu.
+func (o *observer) metricAttributes(err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.GenAIOperationNameKey.String(o.operation),
+		semconv.GenAIProviderNameKey.String(providerName),
+		semconv.GenAIRequestModel(o.requestModel),
+	}
+	if o.responseModel != "" {
+		attrs = append(attrs, semconv.GenAIResponseModel(o.responseModel))
+	}
+	if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(errorType(err)))
+	}
+	return attrs
+}
+
+// errorType returns the error.type value for an error: the HTTP status code for
+// API errors, "timeout" or "canceled" for context errors, and the Go type otherwise.
+func errorType(err error) string {
+	if reqErr, ok := openrouter.IsRequestError(err); ok {
+		return fmt.Sprintf("%d", reqErr.StatusCode)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	return fmt.Sprintf("%T", err)
+}
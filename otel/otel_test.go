@@ -0,0 +1,305 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openrouter "github.com/hra42/openrouter-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestInstrumentation() (*Instrumentation, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	instrumentation := New(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	return instrumentation, recorder, reader
+}
+
+func attributeMap(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, attr := range attrs {
+		m[attr.Key] = attr.Value
+	}
+	return m
+}
+
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func TestChatCompleteSpan(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"Rate limit exceeded"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openrouter.ChatCompletionResponse{
+			ID:       "gen-1",
+			Model:    "openai/gpt-4o-2024-08-06",
+			Provider: "OpenAI",
+			Choices: []openrouter.Choice{
+				{Message: openrouter.Message{Role: "assistant", Content: "Hi"}, FinishReason: "stop"},
+			},
+			Usage: openrouter.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15},
+		})
+	}))
+	defer server.Close()
+
+	instrumentation, recorder, reader := newTestInstrumentation()
+	client := openrouter.NewClient(
+		openrouter.WithAPIKey("test-key"),
+		openrouter.WithBaseURL(server.URL),
+		openrouter.WithRetry(1, time.Millisecond),
+		openrouter.WithInstrumentation(instrumentation),
+	)
+
+	_, err := client.ChatComplete(context.Background(),
+		[]openrouter.Message{openrouter.CreateUserMessage("Hello")},
+		openrouter.WithModel("openai/gpt-4o"),
+		openrouter.WithTemperature(0.5),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name() != "chat openai/gpt-4o" {
+		t.Errorf("unexpected span name %q", span.Name())
+	}
+
+	attrs := attributeMap(span.Attributes())
+	expected := map[attribute.Key]string{
+		"gen_ai.operation.name": "chat",
+		"gen_ai.provider.name":  "openrouter",
+		"gen_ai.request.model":  "openai/gpt-4o",
+		"gen_ai.response.model": "openai/gpt-4o-2024-08-06",
+		"gen_ai.response.id":    "gen-1",
+		"openrouter.provider":   "OpenAI",
+	}
+	for key, value := range expected {
+		if got := attrs[key].AsString(); got != value {
+			t.Errorf("expected %s=%q, got %q", key, value, got)
+		}
+	}
+	if got := attrs["gen_ai.usage.input_tokens"].AsInt64(); got != 12 {
+		t.Errorf("expected 12 input tokens, got %d", got)
+	}
+	if got := attrs["gen_ai.usage.output_tokens"].AsInt64(); got != 3 {
+		t.Errorf("expected 3 output tokens, got %d", got)
+	}
+	if got := attrs["gen_ai.request.temperature"].AsFloat64(); got != 0.5 {
+		t.Errorf("expected temperature 0.5, got %v", got)
+	}
+	if got := attrs["gen_ai.response.finish_reasons"].AsStringSlice(); len(got) != 1 || got[0] != "stop" {
+		t.Errorf("unexpected finish reasons %v", got)
+	}
+
+	events := span.Events()
+	if len(events) != 1 || events[0].Name != "retry" {
+		t.Fatalf("expected one retry event, got %+v", events)
+	}
+	eventAttrs := attributeMap(events[0].Attributes)
+	if eventAttrs[RetryAttemptKey].AsInt64() != 1 || eventAttrs["error.type"].AsString() != "429" {
+		t.Errorf("unexpected retry event attributes: %v", events[0].Attributes)
+	}
+
+	metrics := collectMetrics(t, reader)
+	duration, ok := metrics[OperationDurationMetric].(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 1 || duration.DataPoints[0].Count != 1 {
+		t.Errorf("expected one duration measurement, got %+v", metrics[OperationDurationMetric])
+	}
+
+	tokens, ok := metrics[TokenUsageMetric].(metricdata.Histogram[int64])
+	if !ok || len(tokens.DataPoints) != 2 {
+		t.Fatalf("expected input and output token measurements, got %+v", metrics[TokenUsageMetric])
+	}
+	for _, dp := range tokens.DataPoints {
+		tokenType, _ := dp.Attributes.Value("gen_ai.token.type")
+		if (tokenType.AsString() == "input" && dp.Sum != 12) || (tokenType.AsString() == "output" && dp.Sum != 3) {
+			t.Errorf("unexpected %s token measurement %d", tokenType.AsString(), dp.Sum)
+		}
+	}
+}
+
+func TestChatCompleteStreamSpan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"id\":\"gen-2\",\"model\":\"anthropic/claude-3.5-sonnet\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\"}}]}\n\n"))
+		w.Write([]byte("data: {\"id\":\"gen-2\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\n"))
+		w.Write([]byte("data: {\"id\":\"gen-2\",\"provider\":\"Anthropic\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":1,\"total_tokens\":6}}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	instrumentation, recorder, reader := newTestInstrumentation()
+	client := openrouter.NewClient(
+		openrouter.WithAPIKey("test-key"),
+		openrouter.WithBaseURL(server.URL),
+		openrouter.WithInstrumentation(instrumentation),
+	)
+
+	stream, err := client.ChatCompleteStream(context.Background(),
+		[]openrouter.Message{openrouter.CreateUserMessage("Hello")},
+		openrouter.WithModel("anthropic/claude-3.5-sonnet"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for range stream.Events() {
+	}
+	stream.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+
+	attrs := attributeMap(span.Attributes())
+	if attrs["openrouter.provider"].AsString() != "Anthropic" {
+		t.Errorf("expected provider from final chunk, got %q", attrs["openrouter.provider"].AsString())
+	}
+	if attrs["gen_ai.usage.input_tokens"].AsInt64() != 5 || attrs["gen_ai.usage.output_tokens"].AsInt64() != 1 {
+		t.Errorf("expected usage from final chunk, got %v", span.Attributes())
+	}
+	if got := attrs["gen_ai.response.finish_reasons"].AsStringSlice(); len(got) != 1 || got[0] != "stop" {
+		t.Errorf("unexpected finish reasons %v", got)
+	}
+
+	var firstToken int
+	for _, event := range span.Events() {
+		if event.Name == "first_token" {
+			firstToken++
+		}
+	}
+	if firstToken != 1 {
+		t.Errorf("expected a single first_token event, got %d", firstToken)
+	}
+
+	metrics := collectMetrics(t, reader)
+	ttft, ok := metrics[TimeToFirstTokenMetric].(metricdata.Histogram[float64])
+	if !ok || len(ttft.DataPoints) != 1 || ttft.DataPoints[0].Count != 1 {
+		t.Errorf("expected one time to first token measurement, got %+v", metrics[TimeToFirstTokenMetric])
+	}
+}
+
+func TestTimeToFirstTokenIgnoresSlowReader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"id\":\"gen-3\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	instrumentation, _, reader := newTestInstrumentation()
+	client := openrouter.NewClient(
+		openrouter.WithAPIKey("test-key"),
+		openrouter.WithBaseURL(server.URL),
+		openrouter.WithInstrumentation(instrumentation),
+	)
+
+	stream, err := client.ChatCompleteStream(context.Background(),
+		[]openrouter.Message{openrouter.CreateUserMessage("Hello")},
+		openrouter.WithModel("anthropic/claude-3.5-sonnet"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The chunk arrives long before it is read
+	delay := 300 * time.Millisecond
+	time.Sleep(delay)
+	for range stream.Events() {
+	}
+	stream.Close()
+
+	metrics := collectMetrics(t, reader)
+	ttft, ok := metrics[TimeToFirstTokenMetric].(metricdata.Histogram[float64])
+	if !ok || len(ttft.DataPoints) != 1 || ttft.DataPoints[0].Count != 1 {
+		t.Fatalf("expected one time to first token measurement, got %+v", metrics[TimeToFirstTokenMetric])
+	}
+	if sum := ttft.DataPoints[0].Sum; sum >= delay.Seconds() {
+		t.Errorf("expected the time to first token to exclude the reader's delay, got %.3fs", sum)
+	}
+}
+
+func TestManagementEndpointSpan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid key"}}`))
+	}))
+	defer server.Close()
+
+	instrumentation, recorder, reader := newTestInstrumentation()
+	client := openrouter.NewClient(
+		openrouter.WithAPIKey("test-key"),
+		openrouter.WithBaseURL(server.URL),
+		openrouter.WithInstrumentation(instrumentation),
+	)
+
+	if _, err := client.GetCredits(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name() != "GET /credits" {
+		t.Errorf("unexpected span name %q", span.Name())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", span.Status())
+	}
+
+	attrs := attributeMap(span.Attributes())
+	if attrs["error.type"].AsString() != "401" {
+		t.Errorf("expected error.type 401, got %q", attrs["error.type"].AsString())
+	}
+	if _, ok := attrs["gen_ai.operation.name"]; ok {
+		t.Error("expected no gen_ai attributes on management spans")
+	}
+
+	if metrics := collectMetrics(t, reader); len(metrics) != 0 {
+		t.Errorf("expected no GenAI metrics for management endpoints, got %v", metrics)
+	}
+}
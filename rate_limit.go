@@ -0,0 +1,393 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitInfo contains rate limit state reported by the API in response headers.
+type RateLimitInfo struct {
+	// Limit is the maximum number of requests allowed in the current window (X-RateLimit-Limit)
+	Limit int
+	// Remaining is the number of requests left in the current window (X-RateLimit-Remaining)
+	Remaining int
+	// Reset is when the current window resets (X-RateLimit-Reset); zero if unknown
+	Reset time.Time
+	// ObservedAt is when the headers were received
+	ObservedAt time.Time
+}
+
+// ParseRateLimitInfo extracts rate limit information from response headers.
+// It returns nil if none of the X-RateLimit-* headers are present.
+//
+// X-RateLimit-Reset is accepted as a Unix timestamp in milliseconds or seconds,
+// or as a number of seconds relative to now.
+func ParseRateLimitInfo(header http.Header) *RateLimitInfo {
+	limit := header.Get("X-RateLimit-Limit")
+	remaining := header.Get("X-RateLimit-Remaining")
+	reset := header.Get("X-RateLimit-Reset")
+
+	if limit == "" && remaining == "" && reset == "" {
+		return nil
+	}
+
+	now := time.Now()
+	info := &RateLimitInfo{ObservedAt: now}
+
+	if n, err := strconv.Atoi(strings.TrimSpace(limit)); err == nil {
+		info.Limit = n
+	}
+
+	if n, err := strconv.Atoi(strings.TrimSpace(remaining)); err == nil {
+		info.Remaining = n
+	}
+
+	if n, err := strconv.ParseFloat(strings.TrimSpace(reset), 64); err == nil {
+		switch {
+		case n > 1e12:
+			info.Reset = time.UnixMilli(int64(n))
+		case n > 1e9:
+			info.Reset = time.Unix(int64(n), 0)
+		default:
+			info.Reset = now.Add(time.Duration(n * float64(time.Second)))
+		}
+	}
+
+	return info
+}
+
+// UntilReset returns how long until the rate limit window resets, or 0 if the
+// reset time is unknown or has passed.
+func (r *RateLimitInfo) UntilReset() time.Duration {
+	if r == nil || r.Reset.IsZero() {
+		return 0
+	}
+	if d := time.Until(r.Reset); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header value given either as a number
+// of seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// RateLimitInfo returns the most recent rate limit state observed in API responses,
+// or nil if no rate limit headers have been received yet. Callers can use it to
+// throttle proactively before hitting 429 responses.
+func (c *Client) RateLimitInfo() *RateLimitInfo {
+	c.rateLimitMu.RLock()
+	defer c.rateLimitMu.RUnlock()
+
+	if c.rateLimit == nil {
+		return nil
+	}
+	info := *c.rateLimit
+	return &info
+}
+
+// recordRateLimit stores rate limit state from response headers, if present.
+func (c *Client) recordRateLimit(header http.Header) {
+	info := ParseRateLimitInfo(header)
+	if info == nil {
+		return
+	}
+
+	c.rateLimitMu.Lock()
+	c.rateLimit = info
+	c.rateLimitMu.Unlock()
+}
+
+// Limiter gates outgoing requests on the client side.
+// Implementations must be safe for concurrent use.
+type Limiter interface {
+	// Wait blocks until a request may be sent or the context is cancelled.
+	Wait(ctx context.Context) error
+}
+
+// tokenBucket is a lazily refilled token bucket. Tokens are computed from the
+// elapsed time on each call, so no background goroutine is needed.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full bucket.
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// refill adds the tokens accumulated since the last call. The caller must hold mu.
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// take removes n tokens and returns how long the caller must wait before the
+// tokens are actually available. The balance may go negative, which queues
+// later callers behind earlier ones.
+func (b *tokenBucket) take(n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// give returns n tokens to the bucket.
+func (b *tokenBucket) give(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.tokens += n
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// deficit returns how long until the bucket balance is positive again.
+func (b *tokenBucket) deficit() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens > 0 {
+		return 0
+	}
+	// Wait until at least a fraction of a token is available
+	return time.Duration((-b.tokens/b.rate)*float64(time.Second)) + time.Millisecond
+}
+
+// sleepContext waits for the given duration or until the context is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RateLimiter limits the number of requests per second using a token bucket.
+// It is safe for concurrent use and holds no background resources, so it can
+// be garbage collected without calling Close.
+type RateLimiter struct {
+	bucket *tokenBucket
+}
+
+// NewRateLimiter creates a new rate limiter.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = 10 // Default to 10 requests per second
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		bucket: newTokenBucket(requestsPerSecond, float64(burst)),
+	}
+}
+
+// NewRateLimiterFromKey creates a rate limiter matching an API key's rate limit,
+// as returned by GetKey (e.g. 10 requests per "10s").
+func NewRateLimiterFromKey(limit *KeyRateLimit) (*RateLimiter, error) {
+	if limit == nil || limit.Requests <= 0 {
+		return nil, &ValidationError{Field: "rate_limit", Message: "key has no request rate limit"}
+	}
+
+	interval, err := time.ParseDuration(limit.Interval)
+	if err != nil || interval <= 0 {
+		return nil, &ValidationError{
+			Field:   "rate_limit.interval",
+			Message: fmt.Sprintf("invalid interval %q", limit.Interval),
+		}
+	}
+
+	return NewRateLimiter(limit.Requests/interval.Seconds(), int(limit.Requests)), nil
+}
+
+// Wait blocks until a token is available or the context is cancelled.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	if err := sleepContext(ctx, rl.bucket.take(1)); err != nil {
+		rl.bucket.give(1)
+		return err
+	}
+	return nil
+}
+
+// Close is retained for compatibility. The rate limiter no longer holds
+// background resources, so calling it is not required.
+func (rl *RateLimiter) Close() {}
+
+// TokenRateLimiter budgets model tokens (prompt + completion) per minute.
+// Requests wait while the budget is exhausted; the actual usage reported by
+// each response is deducted once it is known. It is safe for concurrent use.
+type TokenRateLimiter struct {
+	bucket *tokenBucket
+}
+
+// NewTokenRateLimiter creates a limiter allowing the given number of tokens per minute.
+func NewTokenRateLimiter(tokensPerMinute int) *TokenRateLimiter {
+	if tokensPerMinute <= 0 {
+		tokensPerMinute = 1
+	}
+
+	return &TokenRateLimiter{
+		bucket: newTokenBucket(float64(tokensPerMinute)/60, float64(tokensPerMinute)),
+	}
+}
+
+// Wait blocks while the token budget is exhausted or until the context is cancelled.
+func (tl *TokenRateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := tl.bucket.deficit()
+		if wait == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Consume deducts tokens from the budget. The client calls it with the usage
+// reported by each response; it can also be called manually.
+func (tl *TokenRateLimiter) Consume(tokens int) {
+	if tokens > 0 {
+		tl.bucket.take(float64(tokens))
+	}
+}
+
+// waitForRateLimit blocks until the configured client-side limiters allow a request.
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	c.limiterMu.RLock()
+	requestLimiter := c.rateLimiter
+	tokenLimiter := c.tokenLimiter
+	c.limiterMu.RUnlock()
+
+	if requestLimiter != nil {
+		if err := requestLimiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if tokenLimiter != nil {
+		if err := tokenLimiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// usageReporter is implemented by responses that carry token usage.
+type usageReporter interface {
+	tokenUsage() Usage
+}
+
+// tokenUsage returns the token usage of the response.
+func (r ChatCompletionResponse) tokenUsage() Usage {
+	return r.Usage
+}
+
+// tokenUsage returns the token usage of the response.
+func (r CompletionResponse) tokenUsage() Usage {
+	return r.Usage
+}
+
+// recordUsage deducts reported token usage from the token limiter, if any.
+func (c *Client) recordUsage(usage Usage) {
+	c.limiterMu.RLock()
+	tokenLimiter := c.tokenLimiter
+	c.limiterMu.RUnlock()
+
+	if tokenLimiter == nil {
+		return
+	}
+
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.PromptTokens + usage.CompletionTokens
+	}
+	tokenLimiter.Consume(total)
+}
+
+// ConfigureRateLimitFromKey fetches the current key's rate limit with GetKey and
+// installs a matching request rate limiter on the client, replacing any limiter
+// set with WithRateLimiter.
+//
+// Example:
+//
+//	if _, err := client.ConfigureRateLimitFromKey(ctx); err != nil {
+//	    log.Printf("using no client-side rate limit: %v", err)
+//	}
+func (c *Client) ConfigureRateLimitFromKey(ctx context.Context) (*RateLimiter, error) {
+	key, err := c.GetKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter, err := NewRateLimiterFromKey(key.Data.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	c.limiterMu.Lock()
+	c.rateLimiter = limiter
+	c.limiterMu.Unlock()
+
+	return limiter, nil
+}
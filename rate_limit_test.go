@@ -0,0 +1,369 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitInfo(t *testing.T) {
+	if info := ParseRateLimitInfo(http.Header{}); info != nil {
+		t.Errorf("expected nil for missing headers, got %+v", info)
+	}
+
+	reset := time.Now().Add(30 * time.Second).Truncate(time.Millisecond)
+
+	tests := []struct {
+		name  string
+		reset string
+		check func(t *testing.T, info *RateLimitInfo)
+	}{
+		{
+			name:  "milliseconds timestamp",
+			reset: strconv.FormatInt(reset.UnixMilli(), 10),
+			check: func(t *testing.T, info *RateLimitInfo) {
+				if !info.Reset.Equal(reset) {
+					t.Errorf("expected reset %v, got %v", reset, info.Reset)
+				}
+			},
+		},
+		{
+			name:  "seconds timestamp",
+			reset: strconv.FormatInt(reset.Unix(), 10),
+			check: func(t *testing.T, info *RateLimitInfo) {
+				if info.Reset.Unix() != reset.Unix() {
+					t.Errorf("expected reset %v, got %v", reset.Unix(), info.Reset.Unix())
+				}
+			},
+		},
+		{
+			name:  "relative seconds",
+			reset: "30",
+			check: func(t *testing.T, info *RateLimitInfo) {
+				if d := info.UntilReset(); d < 29*time.Second || d > 30*time.Second {
+					t.Errorf("expected reset in ~30s, got %v", d)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("X-RateLimit-Limit", "100")
+			header.Set("X-RateLimit-Remaining", "7")
+			header.Set("X-RateLimit-Reset", tt.reset)
+
+			info := ParseRateLimitInfo(header)
+			if info == nil {
+				t.Fatal("expected rate limit info")
+			}
+			if info.Limit != 100 || info.Remaining != 7 {
+				t.Errorf("unexpected limit/remaining: %d/%d", info.Limit, info.Remaining)
+			}
+			tt.check(t, info)
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("5"); !ok || d != 5*time.Second {
+		t.Errorf("expected 5s, got %v (ok=%v)", d, ok)
+	}
+
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date); !ok || d < 8*time.Second || d > 10*time.Second {
+		t.Errorf("expected ~10s, got %v (ok=%v)", d, ok)
+	}
+
+	past := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(past); !ok || d != 0 {
+		t.Errorf("expected 0 for past date, got %v (ok=%v)", d, ok)
+	}
+
+	for _, invalid := range []string{"", "soon", "-1"} {
+		if _, ok := parseRetryAfter(invalid); ok {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestRetryDelayHonorsServerHints(t *testing.T) {
+	config := &RetryConfig{
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     2 * time.Second,
+		Multiplier:   2,
+	}
+
+	retryAfter := &RequestError{StatusCode: 503, Headers: http.Header{"Retry-After": []string{"1"}}}
+	if d := config.retryDelay(1, retryAfter); d != time.Second {
+		t.Errorf("expected Retry-After delay of 1s, got %v", d)
+	}
+
+	capped := &RequestError{StatusCode: 429, Headers: http.Header{"Retry-After": []string{"120"}}}
+	if d := config.retryDelay(1, capped); d != config.MaxDelay {
+		t.Errorf("expected delay capped at %v, got %v", config.MaxDelay, d)
+	}
+
+	reset := &RequestError{
+		StatusCode: 429,
+		Headers:    http.Header{},
+		RateLimit:  &RateLimitInfo{Reset: time.Now().Add(time.Second)},
+	}
+	if d := config.retryDelay(1, reset); d < 900*time.Millisecond || d > time.Second {
+		t.Errorf("expected delay until rate limit reset, got %v", d)
+	}
+
+	plain := &RequestError{StatusCode: 500}
+	if d := config.retryDelay(2, plain); d != 20*time.Millisecond {
+		t.Errorf("expected exponential backoff of 20ms, got %v", d)
+	}
+
+	if d := config.retryDelay(1, errors.New("network error")); d != 10*time.Millisecond {
+		t.Errorf("expected exponential backoff of 10ms, got %v", d)
+	}
+}
+
+func TestDoRequestRetryAfter(t *testing.T) {
+	attempts := 0
+	var firstAttempt, secondAttempt time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+
+		if attempts == 1 {
+			firstAttempt = time.Now()
+			w.Header().Set("Retry-After", "0.2")
+			w.WriteHeader(429)
+			w.Write([]byte(`{"error":{"message":"Rate limit exceeded","type":"rate_limit_error"}}`))
+			return
+		}
+
+		secondAttempt = time.Now()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ChatCompletionResponse{ID: "success"})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetry(1, time.Millisecond),
+	)
+
+	var resp ChatCompletionResponse
+	if err := client.doRequest(context.Background(), "GET", "/test", nil, &resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+	if wait := secondAttempt.Sub(firstAttempt); wait < 200*time.Millisecond {
+		t.Errorf("expected retry to wait for Retry-After, waited %v", wait)
+	}
+
+	info := client.RateLimitInfo()
+	if info == nil || info.Limit != 10 || info.Remaining != 0 {
+		t.Errorf("expected rate limit info to be recorded, got %+v", info)
+	}
+}
+
+func TestRequestErrorHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.WriteHeader(429)
+		w.Write([]byte(`{"error":{"message":"Rate limit exceeded","type":"rate_limit_error"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithRetry(0, 0))
+
+	err := client.doRequest(context.Background(), "GET", "/test", nil, nil)
+	reqErr, ok := IsRequestError(err)
+	if !ok {
+		t.Fatalf("expected RequestError, got %v", err)
+	}
+
+	if d, ok := reqErr.RetryAfter(); !ok || d != 3*time.Second {
+		t.Errorf("expected Retry-After of 3s, got %v (ok=%v)", d, ok)
+	}
+	if reqErr.RateLimit == nil || reqErr.RateLimit.Remaining != 0 {
+		t.Errorf("expected parsed rate limit info, got %+v", reqErr.RateLimit)
+	}
+	if reqErr.Message != "Rate limit exceeded" {
+		t.Errorf("unexpected message: %q", reqErr.Message)
+	}
+}
+
+func TestRateLimiterThrottles(t *testing.T) {
+	limiter := NewRateLimiter(20, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Two requests use the burst, the other two wait ~50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected limiter to throttle after burst, took %v", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	// The cancelled wait must give its token back
+	if wait := limiter.bucket.take(0); wait > time.Second {
+		t.Errorf("expected cancelled wait to release its reservation, next wait %v", wait)
+	}
+}
+
+func TestNewRateLimiterFromKey(t *testing.T) {
+	limiter, err := NewRateLimiterFromKey(&KeyRateLimit{Interval: "10s", Requests: 20})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limiter.bucket.rate != 2 || limiter.bucket.burst != 20 {
+		t.Errorf("expected 2 rps with burst 20, got %v rps with burst %v", limiter.bucket.rate, limiter.bucket.burst)
+	}
+
+	invalid := []*KeyRateLimit{
+		nil,
+		{Interval: "10s", Requests: 0},
+		{Interval: "soon", Requests: 10},
+		{Interval: "0s", Requests: 10},
+	}
+	for _, limit := range invalid {
+		if _, err := NewRateLimiterFromKey(limit); err == nil {
+			t.Errorf("expected error for %+v", limit)
+		}
+	}
+}
+
+func TestTokenRateLimiter(t *testing.T) {
+	limiter := NewTokenRateLimiter(6000) // 100 tokens per second
+	ctx := context.Background()
+
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	limiter.Consume(6010)
+
+	start := time.Now()
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected wait for budget to refill, took %v", elapsed)
+	}
+
+	limiter.Consume(10000)
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(cancelled); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ChatCompletionResponse{ID: "ok"})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRateLimiter(NewRateLimiter(10, 1)),
+	)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("m")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(times) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(times))
+	}
+	if elapsed := times[2].Sub(times[0]); elapsed < 180*time.Millisecond {
+		t.Errorf("expected requests to be spaced ~100ms apart, spanned %v", elapsed)
+	}
+}
+
+func TestClientTokenRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ChatCompletionResponse{
+			ID:    "ok",
+			Usage: Usage{PromptTokens: 500, CompletionTokens: 100, TotalTokens: 600},
+		})
+	}))
+	defer server.Close()
+
+	limiter := NewTokenRateLimiter(1000)
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithTokenRateLimiter(limiter))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("m")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The budget is used up, so the next request must wait for it to refill
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.ChatComplete(ctx, []Message{CreateUserMessage("hi")}, WithModel("m"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected request to be held back by the token budget, got %v", err)
+	}
+}
+
+func TestConfigureRateLimitFromKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/key" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"label":"test","rate_limit":{"interval":"10s","requests":50}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	limiter, err := client.ConfigureRateLimitFromKey(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limiter.bucket.rate != 5 {
+		t.Errorf("expected 5 rps, got %v", limiter.bucket.rate)
+	}
+	if client.rateLimiter != Limiter(limiter) {
+		t.Error("expected limiter to be installed on the client")
+	}
+}
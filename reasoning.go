@@ -0,0 +1,25 @@
+package openrouter
+
+// ReasoningEffort represents how much effort a model spends on reasoning.
+type ReasoningEffort string
+
+const (
+	// ReasoningEffortHigh allocates a large share of max_tokens to reasoning
+	ReasoningEffortHigh ReasoningEffort = "high"
+	// ReasoningEffortMedium allocates a moderate share of max_tokens to reasoning
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	// ReasoningEffortLow allocates a small share of max_tokens to reasoning
+	ReasoningEffortLow ReasoningEffort = "low"
+	// ReasoningEffortMinimal allocates the smallest share of max_tokens to reasoning
+	ReasoningEffortMinimal ReasoningEffort = "minimal"
+)
+
+// Reasoning detail block types.
+const (
+	// ReasoningDetailText is a block of raw reasoning text
+	ReasoningDetailText = "reasoning.text"
+	// ReasoningDetailSummary is a summary of the reasoning
+	ReasoningDetailSummary = "reasoning.summary"
+	// ReasoningDetailEncrypted is reasoning the provider returns only in encrypted form
+	ReasoningDetailEncrypted = "reasoning.encrypted"
+)
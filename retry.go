@@ -2,6 +2,7 @@ package openrouter
 
 import (
 	"context"
+	"errors"
 	"math"
 	"math/rand"
 	"time"
@@ -26,6 +27,9 @@ type RetryConfig struct {
 	Multiplier     float64
 	Jitter         bool
 	RetryableError func(error) bool
+	// OnRetry is called before waiting for each retry with the upcoming attempt
+	// number (starting at 1), the error that triggered it and the delay.
+	OnRetry func(attempt int, err error, delay time.Duration)
 }
 
 // DefaultRetryConfig returns the default retry configuration.
@@ -74,7 +78,35 @@ func (rc *RetryConfig) calculateBackoff(attempt int) time.Duration {
 	return time.Duration(delay)
 }
 
+// retryDelay returns how long to wait before the given retry attempt.
+// Server-provided delays take precedence over exponential backoff: the Retry-After
+// header is honored for any retryable error, and on rate limit errors without it the
+// X-RateLimit-Reset time is used. Server-provided delays are capped at MaxDelay.
+func (rc *RetryConfig) retryDelay(attempt int, err error) time.Duration {
+	reqErr, ok := IsRequestError(err)
+	if !ok {
+		return rc.calculateBackoff(attempt)
+	}
+
+	delay, ok := reqErr.RetryAfter()
+	if !ok && reqErr.IsRateLimitError() && reqErr.RateLimit != nil {
+		delay = reqErr.RateLimit.UntilReset()
+		ok = delay > 0
+	}
+	if !ok {
+		return rc.calculateBackoff(attempt)
+	}
+
+	if rc.MaxDelay > 0 && delay > rc.MaxDelay {
+		delay = rc.MaxDelay
+	}
+
+	return delay
+}
+
 // RetryWithBackoff executes a function with exponential backoff retry logic.
+// When the error carries a Retry-After header (or, for rate limit errors, an
+// X-RateLimit-Reset time), that delay is used instead of the calculated backoff.
 func RetryWithBackoff(ctx context.Context, config *RetryConfig, fn func() error) error {
 	if config == nil {
 		config = DefaultRetryConfig()
@@ -102,14 +134,10 @@ func RetryWithBackoff(ctx context.Context, config *RetryConfig, fn func() error)
 		}
 
 		// Calculate backoff duration
-		backoff := config.calculateBackoff(attempt + 1)
-
-		// Handle special case for rate limit errors
-		if reqErr, ok := err.(*RequestError); ok && reqErr.IsRateLimitError() {
-			// If we have a Retry-After header value, use it
-			// For now, we'll use our calculated backoff
-			// In a real implementation, we'd parse the Retry-After header
-			backoff = config.calculateBackoff(attempt + 1)
+		backoff := config.retryDelay(attempt+1, err)
+
+		if config.OnRetry != nil {
+			config.OnRetry(attempt+1, err, backoff)
 		}
 
 		// Wait with context cancellation support
@@ -128,99 +156,93 @@ func RetryWithBackoff(ctx context.Context, config *RetryConfig, fn func() error)
 	return nil
 }
 
-// RateLimiter provides rate limiting functionality.
-type RateLimiter struct {
-	requestsPerSecond float64
-	burst             int
-	tokens            chan struct{}
-	ticker            *time.Ticker
-	done              chan struct{}
+// retryConfigKey is the context key for per-request retry configuration.
+type retryConfigKey struct{}
+
+// ContextWithRetryConfig returns a context that overrides the client's retry
+// configuration for requests made with it. This works for every client method,
+// including the management endpoints that take no request options.
+//
+// Example:
+//
+//	// Fail fast for a latency-sensitive call
+//	ctx := openrouter.ContextWithRetryConfig(ctx, &openrouter.RetryConfig{MaxRetries: 0})
+//	credits, err := client.GetCredits(ctx)
+func ContextWithRetryConfig(ctx context.Context, config *RetryConfig) context.Context {
+	return context.WithValue(ctx, retryConfigKey{}, config)
 }
 
-// NewRateLimiter creates a new rate limiter.
-func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
-	if requestsPerSecond <= 0 {
-		requestsPerSecond = 10 // Default to 10 requests per second
+// resolveRetryConfig resolves the retry configuration for a request. A configuration in
+// the context takes precedence over WithRetryConfig, which takes precedence over WithRetry.
+func (c *Client) resolveRetryConfig(ctx context.Context) *RetryConfig {
+	config, _ := ctx.Value(retryConfigKey{}).(*RetryConfig)
+	if config == nil {
+		config = c.retryConfig
 	}
-	if burst <= 0 {
-		burst = 1
+	if config == nil {
+		return &RetryConfig{
+			MaxRetries:     c.maxRetries,
+			InitialDelay:   c.retryDelay,
+			MaxDelay:       defaultMaxDelay,
+			Multiplier:     defaultMultiplier,
+			Jitter:         true,
+			RetryableError: isRetryableClientError,
+		}
 	}
 
-	rl := &RateLimiter{
-		requestsPerSecond: requestsPerSecond,
-		burst:             burst,
-		tokens:            make(chan struct{}, burst),
-		ticker:            time.NewTicker(time.Duration(float64(time.Second) / requestsPerSecond)),
-		done:              make(chan struct{}),
+	// Fill in unset fields so partially specified configurations behave sensibly
+	resolved := *config
+	if resolved.InitialDelay <= 0 {
+		resolved.InitialDelay = c.retryDelay
 	}
-
-	// Fill the token bucket initially
-	for i := 0; i < burst; i++ {
-		rl.tokens <- struct{}{}
+	if resolved.MaxDelay <= 0 {
+		resolved.MaxDelay = defaultMaxDelay
+	}
+	if resolved.Multiplier <= 0 {
+		resolved.Multiplier = defaultMultiplier
+	}
+	if resolved.RetryableError == nil {
+		resolved.RetryableError = isRetryableClientError
 	}
 
-	// Start the token replenishment goroutine
-	go rl.refill()
-
-	return rl
+	return &resolved
 }
 
-// refill continuously adds tokens to the bucket.
-func (rl *RateLimiter) refill() {
-	for {
-		select {
-		case <-rl.ticker.C:
-			select {
-			case rl.tokens <- struct{}{}:
-				// Token added
-			default:
-				// Bucket is full
-			}
-		case <-rl.done:
-			rl.ticker.Stop()
-			return
+// isRetryableClientError is the client's default retry predicate.
+func isRetryableClientError(err error) bool {
+	if reqErr, ok := err.(*RequestError); ok {
+		// Don't retry client errors except rate limit
+		if reqErr.StatusCode >= 400 && reqErr.StatusCode < 500 {
+			return reqErr.IsRateLimitError()
 		}
+		// Retry server errors
+		return true
 	}
-}
-
-// Wait blocks until a token is available or the context is cancelled.
-func (rl *RateLimiter) Wait(ctx context.Context) error {
-	select {
-	case <-rl.tokens:
-		return nil
-	case <-ctx.Done():
-		return ctx.Err()
+	// Open circuits stay open for the cooldown
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
 	}
-}
-
-// Close stops the rate limiter.
-func (rl *RateLimiter) Close() {
-	close(rl.done)
+	// Retry network errors
+	return true
 }
 
 // doRequest performs an HTTP request to the OpenRouter API with retry logic.
 func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}, v interface{}) error {
-	config := &RetryConfig{
-		MaxRetries:   c.maxRetries,
-		InitialDelay: c.retryDelay,
-		MaxDelay:     defaultMaxDelay,
-		Multiplier:   defaultMultiplier,
-		Jitter:       true,
-		RetryableError: func(err error) bool {
-			if reqErr, ok := err.(*RequestError); ok {
-				// Don't retry client errors except rate limit
-				if reqErr.StatusCode >= 400 && reqErr.StatusCode < 500 {
-					return reqErr.IsRateLimitError()
-				}
-				// Retry server errors
-				return true
-			}
-			// Retry network errors
-			return true
-		},
-	}
+	ctx, observer := c.startOperation(ctx, Operation{
+		Method:   method,
+		Endpoint: endpoint,
+		Request:  body,
+	})
 
-	return RetryWithBackoff(ctx, config, func() error {
+	config := observeRetries(c.resolveRetryConfig(ctx), observer)
+	config = c.logRetries(ctx, config, method, endpoint)
+	err := RetryWithBackoff(ctx, config, func() error {
 		return c.doRequestOnce(ctx, method, endpoint, body, v)
 	})
+
+	if observer != nil {
+		observer.End(v, err)
+	}
+
+	return err
 }
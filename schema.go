@@ -0,0 +1,757 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// JSONSchemaFor builds a strict JSON Schema describing the Go type T.
+//
+// The schema follows the rules of OpenAI-style strict mode: every struct sets
+// "additionalProperties": false and lists all of its properties as required.
+// Fields that may be absent (tagged with omitempty, or pointers) are expressed
+// as nullable instead, e.g. "type": ["string", "null"].
+//
+// Field names come from json tags, and fields tagged with json:"-" or that are
+// unexported are skipped. Embedded structs are flattened like encoding/json does,
+// including its rules for fields that share a name.
+// Two additional struct tags are supported:
+//
+//	description:"..."  adds a description to the property
+//	enum:"a,b,c"       restricts the property, or the elements of a slice or
+//	                   array, to the listed values
+//
+// Supported kinds are bool, integers, floats, strings, structs, slices, arrays,
+// maps with string keys, pointers and interfaces. time.Time is emitted as a
+// date-time string. Channels, functions, complex numbers and recursive types
+// return an error.
+//
+// Maps, interfaces and json.RawMessage have no fixed set of properties, so their
+// schemas ({} or an object with "additionalProperties": <schema>) are rejected by
+// strict mode. WithJSONSchemaFor and ChatCompleteInto send such schemas with
+// strict set to false.
+//
+// Example:
+//
+//	type Weather struct {
+//	    Location    string  `json:"location" description:"City name"`
+//	    Temperature float64 `json:"temperature"`
+//	    Unit        string  `json:"unit" enum:"celsius,fahrenheit"`
+//	}
+//
+//	schema, err := openrouter.JSONSchemaFor[Weather]()
+func JSONSchemaFor[T any]() (map[string]interface{}, error) {
+	return GenerateJSONSchema(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// GenerateJSONSchema builds a strict JSON Schema for the given type.
+// See JSONSchemaFor for the supported types and struct tags.
+func GenerateJSONSchema(t reflect.Type) (map[string]interface{}, error) {
+	schema, _, err := generateJSONSchema(t)
+	return schema, err
+}
+
+// generateJSONSchema builds the schema for a type and reports whether it can be
+// used in strict mode.
+func generateJSONSchema(t reflect.Type) (map[string]interface{}, bool, error) {
+	g := &schemaGenerator{visiting: make(map[reflect.Type]bool), strict: true}
+	schema, err := g.schema(t)
+	if err != nil {
+		return nil, false, err
+	}
+	return schema, g.strict, nil
+}
+
+// schemaGenerator holds state while walking a type.
+type schemaGenerator struct {
+	visiting map[reflect.Type]bool
+	// strict is cleared when a type has no strict mode schema
+	strict bool
+}
+
+// schema builds the schema for a single type.
+func (g *schemaGenerator) schema(t reflect.Type) (map[string]interface{}, error) {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case rawMessageType:
+		g.strict = false
+		return map[string]interface{}{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Interface:
+		g.strict = false
+		return map[string]interface{}{}, nil
+	case reflect.Ptr:
+		elem, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return makeNullable(elem), nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices, but not byte arrays, as base64 strings
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s: only string keys are supported", t.Key())
+		}
+		values, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		g.strict = false
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+
+	return nil, fmt.Errorf("unsupported type %s for JSON schema", t)
+}
+
+// structSchema builds an object schema from a struct type.
+func (g *schemaGenerator) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	if g.visiting[t] {
+		return nil, fmt.Errorf("recursive type %s is not supported", t)
+	}
+	g.visiting[t] = true
+	defer delete(g.visiting, t)
+
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for _, field := range dominantFields(collectStructFields(t, 0, map[reflect.Type]bool{})) {
+		prop, err := g.fieldSchema(field.field, field.omitEmpty)
+		if err != nil {
+			return nil, err
+		}
+		properties[field.name] = prop
+		required = append(required, field.name)
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+// structField is a JSON field of a struct, possibly promoted from an embedded struct.
+type structField struct {
+	field     reflect.StructField
+	name      string
+	omitEmpty bool
+	tagged    bool
+	depth     int
+}
+
+// collectStructFields lists the JSON fields of a struct in field order, including
+// the fields of embedded structs at a greater depth. seen guards against embedded
+// pointer cycles.
+func collectStructFields(t reflect.Type, depth int, seen map[reflect.Type]bool) []structField {
+	seen[t] = true
+	defer delete(seen, t)
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, omitEmpty, skip := parseJSONTag(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				if !seen[fieldType] {
+					fields = append(fields, collectStructFields(fieldType, depth+1, seen)...)
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		tagged := name != ""
+		if !tagged {
+			name = field.Name
+		}
+
+		fields = append(fields, structField{field: field, name: name, omitEmpty: omitEmpty, tagged: tagged, depth: depth})
+	}
+
+	return fields
+}
+
+// dominantFields applies the encoding/json rules for fields sharing a name: the
+// shallowest field wins, a tagged field wins among fields at the same depth, and
+// names that remain ambiguous are dropped.
+func dominantFields(fields []structField) []structField {
+	byName := make(map[string][]structField)
+	for _, field := range fields {
+		byName[field.name] = append(byName[field.name], field)
+	}
+
+	var dominant []structField
+	for _, field := range fields {
+		candidates := byName[field.name]
+		if candidates == nil {
+			// Already resolved
+			continue
+		}
+		delete(byName, field.name)
+
+		if winner, ok := dominantField(candidates); ok {
+			dominant = append(dominant, winner)
+		}
+	}
+
+	return dominant
+}
+
+// dominantField picks the field that encoding/json uses among fields with the same name.
+func dominantField(candidates []structField) (structField, bool) {
+	minDepth := candidates[0].depth
+	for _, field := range candidates[1:] {
+		minDepth = min(minDepth, field.depth)
+	}
+
+	var shallowest, tagged []structField
+	for _, field := range candidates {
+		if field.depth != minDepth {
+			continue
+		}
+		shallowest = append(shallowest, field)
+		if field.tagged {
+			tagged = append(tagged, field)
+		}
+	}
+
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	}
+	return structField{}, false
+}
+
+// fieldSchema builds the schema of a struct field, applying its struct tags.
+func (g *schemaGenerator) fieldSchema(field reflect.StructField, omitEmpty bool) (map[string]interface{}, error) {
+	// Pointers are made nullable once, after the description and enum are applied
+	valueType := field.Type
+	nullable := omitEmpty
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+		nullable = true
+	}
+
+	prop, err := g.schema(valueType)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+
+	if description := field.Tag.Get("description"); description != "" {
+		prop["description"] = description
+	}
+
+	if enum := field.Tag.Get("enum"); enum != "" {
+		// The values of slices and arrays restrict their elements
+		target, enumType, nullableItem := prop, valueType, false
+		if items, ok := prop["items"].(map[string]interface{}); ok {
+			target, enumType = items, valueType.Elem()
+			if enumType.Kind() == reflect.Ptr {
+				enumType, nullableItem = enumType.Elem(), true
+			}
+		}
+
+		values, err := parseEnumTag(enum, enumType)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if nullableItem {
+			values = append(values, nil)
+		}
+		target["enum"] = values
+	}
+
+	if nullable {
+		prop = makeNullable(prop)
+	}
+
+	return prop, nil
+}
+
+// parseJSONTag returns the JSON name and omitempty flag of a struct field.
+func parseJSONTag(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+// parseEnumTag converts a comma-separated enum tag into values of the field's kind.
+func parseEnumTag(tag string, t reflect.Type) ([]interface{}, error) {
+	var values []interface{}
+	for _, raw := range strings.Split(tag, ",") {
+		raw = strings.TrimSpace(raw)
+
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer enum value %q", raw)
+			}
+			values = append(values, v)
+		case reflect.Float32, reflect.Float64:
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number enum value %q", raw)
+			}
+			values = append(values, v)
+		case reflect.Bool:
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid boolean enum value %q", raw)
+			}
+			values = append(values, v)
+		default:
+			values = append(values, raw)
+		}
+	}
+
+	return values, nil
+}
+
+// makeNullable allows null in addition to the schema's existing type.
+func makeNullable(schema map[string]interface{}) map[string]interface{} {
+	switch typ := schema["type"].(type) {
+	case string:
+		schema["type"] = []interface{}{typ, "null"}
+	case []interface{}:
+		if !containsValue(typ, "null") {
+			schema["type"] = append(typ, "null")
+		}
+	default:
+		// Schemas without a type already accept null
+		return schema
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, nil) {
+		schema["enum"] = append(enum, nil)
+	}
+
+	return schema
+}
+
+// containsValue reports whether values contains v.
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, existing := range values {
+		if existing == v {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaName derives a JSON schema name from a Go type name.
+func schemaName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		// Strip type arguments from generic type names
+		name = name[:i]
+	}
+
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	if b.Len() == 0 {
+		return "response"
+	}
+
+	return b.String()
+}
+
+// schemaResponseFormat returns the JSON schema response format for T. Types that
+// cannot be described by a JSON schema return a *ValidationError.
+func schemaResponseFormat[T any]() (*ResponseFormat, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	schema, strict, err := generateJSONSchema(t)
+	if err != nil {
+		return nil, &ValidationError{
+			Field:   "response_format",
+			Message: fmt.Sprintf("cannot generate JSON schema for %s: %v", t, err),
+		}
+	}
+
+	return &ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &JSONSchema{
+			Name:   schemaName(t),
+			Strict: strict,
+			Schema: schema,
+		},
+	}, nil
+}
+
+// WithJSONSchemaFor sets a JSON schema response format generated from the Go type T.
+// The schema name is derived from the type name (e.g. WeatherReport → "weather_report").
+// Strict mode is enabled unless T contains maps, interfaces or json.RawMessage.
+// If T cannot be described by a JSON schema, such as a recursive type, the request
+// fails with a *ValidationError.
+func WithJSONSchemaFor[T any]() ChatCompletionOption {
+	format, err := schemaResponseFormat[T]()
+	return func(r *ChatCompletionRequest) {
+		if err != nil {
+			setOptionError(r, err)
+			return
+		}
+		setResponseFormat(r, format)
+	}
+}
+
+// WithCompletionJSONSchemaFor sets a JSON schema response format generated from the Go
+// type T for completion requests. Strict mode is enabled as with WithJSONSchemaFor, and
+// types that cannot be described by a JSON schema fail the request.
+func WithCompletionJSONSchemaFor[T any]() CompletionOption {
+	format, err := schemaResponseFormat[T]()
+	return func(r *CompletionRequest) {
+		if err != nil {
+			setOptionError(r, err)
+			return
+		}
+		setResponseFormat(r, format)
+	}
+}
+
+// NewToolFromFunc creates a tool definition whose parameters are generated from the
+// struct type T, together with a ToolHandler that decodes the model's arguments into
+// T before calling fn. The result can be registered directly with a ToolRunner.
+//
+// Example:
+//
+//	type WeatherArgs struct {
+//	    Location string `json:"location" description:"City name"`
+//	}
+//
+//	tool, handler, err := openrouter.NewToolFromFunc("get_weather", "Get the current weather",
+//	    func(ctx context.Context, args WeatherArgs) (string, error) {
+//	        return lookupWeather(args.Location)
+//	    })
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	runner := openrouter.NewToolRunner(client, openrouter.WithToolHandler(tool, handler))
+func NewToolFromFunc[T any](name, description string, fn func(context.Context, T) (string, error)) (Tool, ToolHandler, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return Tool{}, nil, &ValidationError{
+			Field:   "parameters",
+			Message: fmt.Sprintf("tool parameters must be a struct, got %s", t),
+		}
+	}
+
+	schema, err := GenerateJSONSchema(t)
+	if err != nil {
+		return Tool{}, nil, err
+	}
+
+	tool := Tool{
+		Type: "function",
+		Function: Function{
+			Name:        name,
+			Description: description,
+			Parameters:  schema,
+		},
+	}
+
+	handler := func(ctx context.Context, arguments string) (string, error) {
+		var args T
+		if arguments != "" {
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", fmt.Errorf("invalid arguments for %s: %w", name, err)
+			}
+		}
+		return fn(ctx, args)
+	}
+
+	return tool, handler, nil
+}
+
+// ValidateJSON checks that data is valid JSON matching the given schema.
+// It supports the subset of JSON Schema produced by GenerateJSONSchema: type,
+// properties, required, additionalProperties, items and enum. The first
+// mismatch is returned as a *ValidationError whose Field is the JSON path.
+func ValidateJSON(data []byte, schema map[string]interface{}) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	return validateSchemaValue(value, schema, "")
+}
+
+// validateSchemaValue validates a decoded JSON value against a schema.
+func validateSchemaValue(value interface{}, schema map[string]interface{}, path string) error {
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, typ := range types {
+			if jsonValueHasType(value, typ) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &ValidationError{
+				Field:   path,
+				Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value)),
+			}
+		}
+	}
+
+	if enum := toInterfaceSlice(schema["enum"]); enum != nil {
+		allowed := false
+		for _, candidate := range enum {
+			if jsonValuesEqual(value, candidate) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &ValidationError{Field: path, Message: fmt.Sprintf("value %v is not one of %v", value, enum)}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return validateSchemaObject(v, schema, path)
+	case []interface{}:
+		items, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for i, item := range v {
+			if err := validateSchemaValue(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSchemaObject validates the properties of a JSON object.
+func validateSchemaObject(object map[string]interface{}, schema map[string]interface{}, path string) error {
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	for _, name := range toInterfaceSlice(schema["required"]) {
+		key, _ := name.(string)
+		if _, ok := object[key]; !ok {
+			return &ValidationError{Field: joinSchemaPath(path, key), Message: "required property is missing"}
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := joinSchemaPath(path, key)
+
+		if prop, ok := properties[key].(map[string]interface{}); ok {
+			if err := validateSchemaValue(object[key], prop, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return &ValidationError{Field: fieldPath, Message: "additional property is not allowed"}
+			}
+		case map[string]interface{}:
+			if err := validateSchemaValue(object[key], additional, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// schemaTypes returns the type names declared by a schema "type" keyword.
+func schemaTypes(typ interface{}) []string {
+	switch t := typ.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// toInterfaceSlice converts the slice forms used in schemas to []interface{}.
+func toInterfaceSlice(v interface{}) []interface{} {
+	switch s := v.(type) {
+	case []interface{}:
+		return s
+	case []string:
+		result := make([]interface{}, len(s))
+		for i, item := range s {
+			result[i] = item
+		}
+		return result
+	}
+	return nil
+}
+
+// jsonValueHasType reports whether a decoded JSON value matches a JSON Schema type name.
+func jsonValueHasType(value interface{}, typ string) bool {
+	switch typ {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+// jsonTypeName returns the JSON Schema type name of a decoded JSON value.
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonValuesEqual compares a decoded JSON value with an enum candidate from a schema.
+func jsonValuesEqual(value, candidate interface{}) bool {
+	switch v := value.(type) {
+	case float64:
+		switch c := candidate.(type) {
+		case int64:
+			return v == float64(c)
+		case int:
+			return v == float64(c)
+		case float64:
+			return v == c
+		}
+		return false
+	case map[string]interface{}, []interface{}:
+		// Only scalar enum values are supported
+		return false
+	}
+	return value == candidate
+}
+
+// joinSchemaPath appends a property name to a JSON path.
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
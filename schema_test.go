@@ -0,0 +1,363 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	City    string `json:"city"`
+	Country string `json:"country,omitempty"`
+}
+
+type schemaBase struct {
+	ID string `json:"id"`
+}
+
+type schemaPerson struct {
+	schemaBase
+	Name      string            `json:"name" description:"Full name"`
+	Age       int               `json:"age"`
+	Score     float64           `json:"score"`
+	Active    bool              `json:"active"`
+	Role      string            `json:"role" enum:"admin,user"`
+	Level     *int              `json:"level" enum:"1,2,3"`
+	Tags      []string          `json:"tags"`
+	Address   schemaAddress     `json:"address"`
+	Previous  *schemaAddress    `json:"previous"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Extra     interface{}       `json:"extra"`
+	Ignored   string            `json:"-"`
+	internal  string
+}
+
+type schemaNode struct {
+	Value    string       `json:"value"`
+	Children []schemaNode `json:"children"`
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	return string(data)
+}
+
+func TestJSONSchemaFor(t *testing.T) {
+	schema, err := JSONSchemaFor[schemaPerson]()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if schema["type"] != "object" {
+		t.Errorf("expected type object, got %v", schema["type"])
+	}
+	if schema["additionalProperties"] != false {
+		t.Error("expected additionalProperties to be false")
+	}
+
+	required := schema["required"].([]string)
+	expectedRequired := []string{"id", "name", "age", "score", "active", "role", "level", "tags", "address", "previous", "labels", "created_at", "extra"}
+	if !reflect.DeepEqual(required, expectedRequired) {
+		t.Errorf("expected required %v, got %v", expectedRequired, required)
+	}
+
+	props := schema["properties"].(map[string]interface{})
+	if _, ok := props["Ignored"]; ok {
+		t.Error("expected json:\"-\" field to be skipped")
+	}
+	if _, ok := props["internal"]; ok {
+		t.Error("expected unexported field to be skipped")
+	}
+
+	tests := []struct {
+		property string
+		expected string
+	}{
+		{"id", `{"type":"string"}`},
+		{"name", `{"description":"Full name","type":"string"}`},
+		{"age", `{"type":"integer"}`},
+		{"score", `{"type":"number"}`},
+		{"active", `{"type":"boolean"}`},
+		{"role", `{"enum":["admin","user"],"type":"string"}`},
+		{"level", `{"enum":[1,2,3,null],"type":["integer","null"]}`},
+		{"tags", `{"items":{"type":"string"},"type":"array"}`},
+		{"address", `{"additionalProperties":false,"properties":{"city":{"type":"string"},"country":{"type":["string","null"]}},"required":["city","country"],"type":"object"}`},
+		{"labels", `{"additionalProperties":{"type":"string"},"type":["object","null"]}`},
+		{"created_at", `{"format":"date-time","type":"string"}`},
+		{"extra", `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			got := mustJSON(t, props[tt.property])
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	previous := props["previous"].(map[string]interface{})
+	if !reflect.DeepEqual(previous["type"], []interface{}{"object", "null"}) {
+		t.Errorf("expected pointer struct to be nullable, got %v", previous["type"])
+	}
+}
+
+func TestJSONSchemaForUnsupportedTypes(t *testing.T) {
+	if _, err := JSONSchemaFor[schemaNode](); err == nil {
+		t.Error("expected error for recursive type")
+	}
+
+	type withChan struct {
+		C chan int `json:"c"`
+	}
+	if _, err := JSONSchemaFor[withChan](); err == nil {
+		t.Error("expected error for channel field")
+	}
+
+	type withIntKeys struct {
+		M map[int]string `json:"m"`
+	}
+	if _, err := JSONSchemaFor[withIntKeys](); err == nil {
+		t.Error("expected error for non-string map keys")
+	}
+
+	type badEnum struct {
+		N int `json:"n" enum:"one"`
+	}
+	if _, err := JSONSchemaFor[badEnum](); err == nil {
+		t.Error("expected error for invalid integer enum")
+	}
+}
+
+func TestSchemaName(t *testing.T) {
+	tests := []struct {
+		t        reflect.Type
+		expected string
+	}{
+		{reflect.TypeOf(schemaPerson{}), "schema_person"},
+		{reflect.TypeOf(&schemaAddress{}), "schema_address"},
+		{reflect.TypeOf([]schemaNode{}), "schema_node"},
+		{reflect.TypeOf(struct{ A int }{}), "response"},
+		{reflect.TypeOf(HTTPSettings{}), "http_settings"},
+	}
+
+	for _, tt := range tests {
+		if got := schemaName(tt.t); got != tt.expected {
+			t.Errorf("schemaName(%s) = %q, expected %q", tt.t, got, tt.expected)
+		}
+	}
+}
+
+type HTTPSettings struct{}
+
+type schemaShadowed struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Label string
+}
+
+type schemaOther struct {
+	Name  string `json:"name"`
+	Label string
+	Note  string `json:"note"`
+}
+
+type schemaShadowing struct {
+	schemaShadowed
+	*schemaOther
+	ID string `json:"id"`
+}
+
+func TestJSONSchemaForByteArrays(t *testing.T) {
+	type checksum struct {
+		Digest [4]byte `json:"digest"`
+		Data   []byte  `json:"data"`
+	}
+
+	schema, err := JSONSchemaFor[checksum]()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Byte arrays are encoded as arrays of numbers, byte slices as base64
+	data := []byte(mustJSON(t, checksum{Digest: [4]byte{1, 2, 3, 4}, Data: []byte("hi")}))
+	if err := ValidateJSON(data, schema); err != nil {
+		t.Errorf("expected encoding/json output to match the schema, got %v", err)
+	}
+}
+
+func TestJSONSchemaForSliceEnum(t *testing.T) {
+	type labels struct {
+		Tags    []string  `json:"tags" enum:"a,b"`
+		Ratings [2]*int   `json:"ratings" enum:"1,2"`
+		Primary *[]string `json:"primary" enum:"a"`
+	}
+
+	schema, err := JSONSchemaFor[labels]()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	if got := mustJSON(t, properties["tags"]); got != `{"items":{"enum":["a","b"],"type":"string"},"type":"array"}` {
+		t.Errorf("expected the enum on the items, got %s", got)
+	}
+	if got := mustJSON(t, properties["ratings"]); got != `{"items":{"enum":[1,2,null],"type":["integer","null"]},"type":"array"}` {
+		t.Errorf("expected a nullable enum on the items, got %s", got)
+	}
+	if got := mustJSON(t, properties["primary"]); got != `{"items":{"enum":["a"],"type":"string"},"type":["array","null"]}` {
+		t.Errorf("expected the enum on the items of a nullable slice, got %s", got)
+	}
+
+	if err := ValidateJSON([]byte(`{"tags":["a","b"],"ratings":[1,null],"primary":null}`), schema); err != nil {
+		t.Errorf("expected valid values to pass, got %v", err)
+	}
+	if err := ValidateJSON([]byte(`{"tags":["c"],"ratings":[1,2],"primary":null}`), schema); err == nil {
+		t.Error("expected an element outside the enum to fail")
+	}
+}
+
+func TestJSONSchemaForShadowedFields(t *testing.T) {
+	schema, err := JSONSchemaFor[schemaShadowing]()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The outer id wins, name and Label are ambiguous and dropped like encoding/json does
+	if got := mustJSON(t, schema["required"]); got != `["id","note"]` {
+		t.Errorf("unexpected required fields: %s", got)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	if got := mustJSON(t, properties["id"]); got != `{"type":"string"}` {
+		t.Errorf("expected the shallower id field, got %s", got)
+	}
+
+	encoded := mustJSON(t, schemaShadowing{schemaOther: &schemaOther{}})
+	if encoded != `{"note":"","id":""}` {
+		t.Errorf("schema does not match encoding/json: %s", encoded)
+	}
+}
+
+func TestWithJSONSchemaFor(t *testing.T) {
+	req := &ChatCompletionRequest{}
+	WithJSONSchemaFor[schemaAddress]()(req)
+
+	if req.ResponseFormat == nil || req.ResponseFormat.JSONSchema == nil {
+		t.Fatal("expected JSON schema response format")
+	}
+	if req.ResponseFormat.Type != "json_schema" {
+		t.Errorf("expected type json_schema, got %q", req.ResponseFormat.Type)
+	}
+	if req.ResponseFormat.JSONSchema.Name != "schema_address" {
+		t.Errorf("expected name schema_address, got %q", req.ResponseFormat.JSONSchema.Name)
+	}
+	if !req.ResponseFormat.JSONSchema.Strict {
+		t.Error("expected strict schema")
+	}
+
+	completionReq := &CompletionRequest{}
+	WithCompletionJSONSchemaFor[schemaAddress]()(completionReq)
+	if completionReq.ResponseFormat == nil || completionReq.ResponseFormat.JSONSchema.Name != "schema_address" {
+		t.Error("expected completion JSON schema response format")
+	}
+
+	// Maps, interfaces and raw JSON have no strict mode schema
+	for name, option := range map[string]ChatCompletionOption{
+		"map":       WithJSONSchemaFor[struct{ Labels map[string]string }](),
+		"interface": WithJSONSchemaFor[struct{ Extra []interface{} }](),
+		"raw":       WithJSONSchemaFor[struct{ Raw *json.RawMessage }](),
+		"person":    WithJSONSchemaFor[schemaPerson](),
+	} {
+		req := &ChatCompletionRequest{}
+		option(req)
+		if req.ResponseFormat.JSONSchema.Strict {
+			t.Errorf("%s: expected strict mode to be disabled", name)
+		}
+	}
+}
+
+type schemaComment struct {
+	Text    string          `json:"text"`
+	Replies []schemaComment `json:"replies"`
+}
+
+func TestWithJSONSchemaForUnsupportedType(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	// Building the option does not panic; the request fails instead
+	_, err := client.ChatComplete(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithJSONSchemaFor[schemaComment](),
+	)
+	if validationErr, ok := IsValidationError(err); !ok || validationErr.Field != "response_format" {
+		t.Errorf("expected a validation error for the recursive type, got %v", err)
+	}
+
+	_, err = client.CompleteStream(context.Background(), "hi",
+		WithCompletionModel("test-model"),
+		WithCompletionJSONSchemaFor[chan int](),
+	)
+	if _, ok := IsValidationError(err); !ok {
+		t.Errorf("expected a validation error for the channel type, got %v", err)
+	}
+
+	if requests != 0 {
+		t.Errorf("expected no requests to be sent, got %d", requests)
+	}
+}
+
+func TestNewToolFromFunc(t *testing.T) {
+	type weatherArgs struct {
+		Location string `json:"location" description:"City name"`
+		Unit     string `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	}
+
+	tool, handler, err := NewToolFromFunc("get_weather", "Get the weather",
+		func(ctx context.Context, args weatherArgs) (string, error) {
+			return args.Location + "/" + args.Unit, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tool.Type != "function" || tool.Function.Name != "get_weather" || tool.Function.Description != "Get the weather" {
+		t.Errorf("unexpected tool: %+v", tool)
+	}
+	if tool.Function.Parameters["type"] != "object" {
+		t.Errorf("expected object parameters, got %v", tool.Function.Parameters["type"])
+	}
+
+	result, err := handler(context.Background(), `{"location":"Paris","unit":"celsius"}`)
+	if err != nil {
+		t.Fatalf("unexpected handler error: %v", err)
+	}
+	if result != "Paris/celsius" {
+		t.Errorf("unexpected handler result: %q", result)
+	}
+
+	if _, err := handler(context.Background(), `{invalid`); err == nil {
+		t.Error("expected error for invalid arguments")
+	}
+
+	_, _, err = NewToolFromFunc("bad", "", func(ctx context.Context, args string) (string, error) {
+		return "", nil
+	})
+	if _, ok := IsValidationError(err); !ok {
+		t.Errorf("expected ValidationError for non-struct parameters, got %v", err)
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
-	"net/http"
+	"iter"
+	"log/slog"
+	"maps"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hra42/openrouter-go/internal/sse"
@@ -18,113 +22,208 @@ import (
 type eventStream struct {
 	ctx       context.Context
 	cancel    context.CancelFunc
-	response  *http.Response
+	reader    io.ReadCloser
 	scanner   *sse.Scanner
 	events    chan StreamEvent
 	err       error
 	errMu     sync.RWMutex
 	closed    bool
 	closeMu   sync.Mutex
-	reconnect bool
+	reconnect *StreamReconnectConfig
 	client    *Client
 	endpoint  string
 	body      interface{}
-}
+	observer  OperationObserver
 
-// setStreamHeaders sets all required headers for SSE streaming requests.
-func (c *Client) setStreamHeaders(req *http.Request) {
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
+	finishOnce sync.Once
 
-	if c.referer != "" {
-		req.Header.Set("HTTP-Referer", c.referer)
-	}
+	// firstEvent is closed when the first event is read, done when reading stops
+	firstEvent     chan struct{}
+	firstEventOnce sync.Once
+	done           chan struct{}
 
-	if c.appName != "" {
-		req.Header.Set("X-Title", c.appName)
-	}
+	// hedge reports the hedged requests that opened the stream, if any
+	hedge *HedgeReport
 
-	// Add custom headers
-	for key, value := range c.customHeaders {
-		req.Header.Set(key, value)
-	}
+	// partial collects the output for ReconnectContinue; reconnected is attached
+	// to the first event after a reconnection
+	partial     *partialOutput
+	reconnected *StreamReconnect
+
+	// activity is signaled when data is received, for the idle timeout;
+	// delivering is set while an event waits for the caller to read it
+	activity   chan struct{}
+	delivering atomic.Bool
+
+	// end reports the outcome of the current connection; abandoned is set when the
+	// stream is closed before reading stops
+	end       atomic.Pointer[streamEnd]
+	abandoned atomic.Bool
+
+	// Most recent usage reported by a chunk
+	usage   *Usage
+	usageMu sync.RWMutex
 }
 
 // createStream creates a new SSE stream for the given endpoint and request.
 func (c *Client) createStream(ctx context.Context, endpoint string, body interface{}) (*eventStream, error) {
-	url := c.baseURL + endpoint
+	ctx, observer := c.startOperation(ctx, Operation{
+		Method:   "POST",
+		Endpoint: endpoint,
+		Request:  body,
+		Stream:   true,
+	})
 
-	jsonData, err := json.Marshal(body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	// Create stream context
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	stream := &eventStream{
+		ctx:       streamCtx,
+		cancel:    cancel,
+		events:    make(chan StreamEvent, 10),
+		reconnect: requestStreamReconnect(body),
+		client:    c,
+		endpoint:  endpoint,
+		body:      body,
+		observer:  observer,
+
+		firstEvent: make(chan struct{}),
+		done:       make(chan struct{}),
+		activity:   make(chan struct{}, 1),
+	}
+
+	if stream.reconnect != nil && stream.reconnect.Strategy == ReconnectContinue {
+		stream.partial = &partialOutput{}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
+	// The first token deadline and the overall cap include opening the stream
+	if timeouts := requestStreamTimeouts(body); timeouts != nil {
+		go stream.watch(*timeouts)
+	}
+
+	reader, end, err := c.openStream(streamCtx, endpoint, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		// A stream timeout that fired while opening takes precedence
+		if timeoutErr := stream.Err(); timeoutErr != nil {
+			err = timeoutErr
+		}
+		cancel()
+		if observer != nil {
+			observer.End(nil, err)
+		}
+		return nil, err
 	}
 
-	// Set headers
-	c.setStreamHeaders(req)
+	stream.reader = stream.track(reader)
+	stream.scanner = sse.NewScanner(stream.reader)
+	stream.end.Store(end)
+	stream.touch()
+
+	// Start reading events
+	go stream.readEvents()
+
+	return stream, nil
+}
+
+// openStream sends a streaming request through the middleware chain and returns
+// the Server-Sent Events body, together with the streamEnd to report its outcome to.
+func (c *Client) openStream(ctx context.Context, endpoint string, body interface{}) (io.ReadCloser, *streamEnd, error) {
+	end := &streamEnd{}
+	ctx = context.WithValue(ctx, streamEndKey{}, end)
+
+	req := &RoundTripRequest{
+		Method:   "POST",
+		Endpoint: endpoint,
+		Body:     body,
+		Header:   c.requestHeaders(body, true),
+		Stream:   true,
+		// Stream timeouts replace the HTTP client's timeout, which would cut off long streams
+		noClientTimeout: requestStreamTimeouts(body) != nil,
+	}
 
-	// Make the request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.roundTrip(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return nil, nil, err
 	}
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(resp.Body)
+	if resp == nil || resp.Stream == nil {
+		// The connection says nothing about the model or provider
+		err := &StreamError{Message: "middleware returned no stream body"}
+		end.finish(err, true)
+		return nil, nil, err
+	}
 
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err != nil {
-			return nil, &RequestError{
-				StatusCode: resp.StatusCode,
-				Message:    string(body),
-			}
-		}
+	return resp.Stream, end, nil
+}
 
-		return nil, &RequestError{
-			StatusCode: resp.StatusCode,
-			Message:    errorResp.Error.Message,
-			Type:       errorResp.Error.Type,
-			Code:       errorResp.Error.Code,
-		}
+// streamEndKey is the context key of the streamEnd of a stream connection.
+type streamEndKey struct{}
+
+// streamEnd lets the middleware that opened a stream connection learn how it ended.
+// openStream carries it in the request context, and the circuit breaker sets report.
+type streamEnd struct {
+	report func(provider string, err error, abandoned bool)
+
+	mu       sync.Mutex
+	provider string
+	reported bool
+}
+
+// streamEndFromContext returns the streamEnd of the stream connection being opened, if any.
+func streamEndFromContext(ctx context.Context) *streamEnd {
+	end, _ := ctx.Value(streamEndKey{}).(*streamEnd)
+	return end
+}
+
+// observe records the provider that serves the connection from a chunk.
+func (e *streamEnd) observe(data []byte) {
+	if e == nil || e.report == nil || !bytes.Contains(data, []byte(`"provider"`)) {
+		return
 	}
 
-	// Create stream context
-	streamCtx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.provider != "" {
+		return
+	}
 
-	stream := &eventStream{
-		ctx:       streamCtx,
-		cancel:    cancel,
-		response:  resp,
-		scanner:   sse.NewScanner(resp.Body),
-		events:    make(chan StreamEvent, 10),
-		reconnect: true,
-		client:    c,
-		endpoint:  endpoint,
-		body:      body,
+	var chunk struct {
+		Provider string `json:"provider"`
 	}
+	if json.Unmarshal(data, &chunk) == nil {
+		e.provider = chunk.Provider
+	}
+}
 
-	// Start reading events
-	go stream.readEvents()
+// finish reports the outcome of the connection. Only the first call has an effect.
+func (e *streamEnd) finish(err error, abandoned bool) {
+	if e == nil || e.report == nil {
+		return
+	}
 
-	return stream, nil
+	e.mu.Lock()
+	if e.reported {
+		e.mu.Unlock()
+		return
+	}
+	e.reported = true
+	provider := e.provider
+	e.mu.Unlock()
+
+	e.report(provider, err, abandoned)
 }
 
 // readEvents reads SSE events from the stream.
 func (es *eventStream) readEvents() {
+	defer close(es.done)
 	defer close(es.events)
-	defer es.response.Body.Close()
+	defer func() {
+		es.closeMu.Lock()
+		es.reader.Close()
+		es.closeMu.Unlock()
+	}()
 
 	retryCount := 0
-	maxRetries := 3
 
 	for {
 		// Check if context is cancelled
@@ -138,10 +237,11 @@ func (es *eventStream) readEvents() {
 		// Read next event
 		if !es.scanner.Scan() {
 			if err := es.scanner.Err(); err != nil {
-				// Handle connection errors with reconnection
-				if es.reconnect && retryCount < maxRetries {
+				// Handle connection errors with reconnection, if enabled.
+				// Streams canceled or timed out are not reconnected
+				if es.reconnect != nil && es.ctx.Err() == nil && retryCount < es.reconnect.maxAttempts() {
 					retryCount++
-					if es.attemptReconnect(retryCount) {
+					if es.attemptReconnect(retryCount, err) {
 						continue
 					}
 				}
@@ -160,19 +260,42 @@ func (es *eventStream) readEvents() {
 			return
 		}
 
+		es.firstEventOnce.Do(func() { close(es.firstEvent) })
+		es.end.Load().observe(event.Data)
+
+		// A provider failing mid-stream ends the stream after the error chunk
+		streamErr := parseStreamErrorEvent(event.Data)
+		if streamErr != nil {
+			es.setError(streamErr)
+		}
+
 		// Convert SSE event to StreamEvent
 		streamEvent := StreamEvent{
-			ID:    event.ID,
-			Event: event.Type,
-			Data:  string(event.Data),
-			Retry: event.Retry,
+			ID:       event.ID,
+			Event:    event.Type,
+			Data:     string(event.Data),
+			Retry:    event.Retry,
+			received: time.Now(),
 		}
+		streamEvent.Reconnect, es.reconnected = es.reconnected, nil
 
-		// Send the event
+		if es.partial != nil {
+			es.partial.add(streamEvent.Data)
+		}
+
+		// Send the event; waiting for the caller to read it is not idle time
+		es.delivering.Store(true)
 		select {
 		case es.events <- streamEvent:
+			es.delivering.Store(false)
+			es.touch()
 			retryCount = 0 // Reset retry count on successful event
 		case <-es.ctx.Done():
+			es.setError(es.ctx.Err())
+			return
+		}
+
+		if streamErr != nil {
 			return
 		}
 	}
@@ -199,6 +322,29 @@ func (es *eventStream) setError(err error) {
 	}
 }
 
+// setUsage stores usage reported by a chunk. Chunks without usage are ignored.
+func (es *eventStream) setUsage(usage Usage) {
+	if usage == (Usage{}) {
+		return
+	}
+
+	es.usageMu.Lock()
+	defer es.usageMu.Unlock()
+	es.usage = &usage
+}
+
+// Usage returns the most recent usage reported by the stream.
+func (es *eventStream) Usage() *Usage {
+	es.usageMu.RLock()
+	defer es.usageMu.RUnlock()
+
+	if es.usage == nil {
+		return nil
+	}
+	usage := *es.usage
+	return &usage
+}
+
 // Close closes the stream.
 func (es *eventStream) Close() error {
 	es.closeMu.Lock()
@@ -208,22 +354,43 @@ func (es *eventStream) Close() error {
 		return nil
 	}
 
+	// A stream closed before reading stopped was abandoned by the consumer
+	select {
+	case <-es.done:
+	default:
+		es.abandoned.Store(true)
+	}
+
 	es.closed = true
-	es.reconnect = false // Disable reconnection on explicit close
-	es.cancel()
+	es.cancel() // Also prevents reconnection
+	es.finish()
 
-	if es.response != nil && es.response.Body != nil {
-		return es.response.Body.Close()
+	if es.reader != nil {
+		return es.reader.Close()
 	}
 
 	return nil
 }
 
-// attemptReconnect attempts to reconnect to the stream.
-func (es *eventStream) attemptReconnect(attempt int) bool {
+// finish reports the end of the stream to the client's instrumentation, logger and
+// circuit breaker. Only the first call has an effect.
+func (es *eventStream) finish() {
+	es.finishOnce.Do(func() {
+		err := es.Err()
+		abandoned := es.abandoned.Load() || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+		es.end.Load().finish(err, abandoned)
+		es.client.logStreamEnd(es.ctx, es.endpoint, err)
+		if es.observer != nil {
+			es.observer.End(nil, err)
+		}
+	})
+}
+
+// attemptReconnect attempts to reconnect to the stream after it failed with cause.
+func (es *eventStream) attemptReconnect(attempt int, cause error) bool {
 	// Close current connection
-	if es.response != nil && es.response.Body != nil {
-		es.response.Body.Close()
+	if es.reader != nil {
+		es.reader.Close()
 	}
 
 	// Calculate backoff
@@ -232,6 +399,22 @@ func (es *eventStream) attemptReconnect(attempt int) bool {
 		backoff = maxReconnectBackoff
 	}
 
+	// Continue after the output so far if possible, otherwise start over
+	strategy, body := ReconnectRestart, es.body
+	if es.partial != nil && es.partial.continuable() {
+		strategy, body = ReconnectContinue, es.partial.continueRequest(es.body)
+	}
+
+	if logger := es.client.logger; logger != nil {
+		logger.WarnContext(es.ctx, "openrouter stream reconnecting",
+			slog.String("endpoint", es.endpoint),
+			slog.Int("attempt", attempt),
+			slog.String("strategy", string(strategy)),
+			slog.Duration("delay", backoff),
+			slog.Any("error", cause),
+		)
+	}
+
 	// Wait before reconnecting
 	select {
 	case <-time.After(backoff):
@@ -240,35 +423,40 @@ func (es *eventStream) attemptReconnect(attempt int) bool {
 	}
 
 	// Attempt to reconnect
-	url := es.client.baseURL + es.endpoint
-
-	jsonData, err := json.Marshal(es.body)
+	reader, end, err := es.client.openStream(es.ctx, es.endpoint, body)
 	if err != nil {
+		if logger := es.client.logger; logger != nil {
+			logger.WarnContext(es.ctx, "openrouter stream reconnect failed",
+				slog.String("endpoint", es.endpoint),
+				slog.Int("attempt", attempt),
+				slog.Any("error", err),
+			)
+		}
 		return false
 	}
 
-	req, err := http.NewRequestWithContext(es.ctx, "POST", url, bytes.NewReader(jsonData))
-	if err != nil {
+	// Update stream with new connection, unless it was closed meanwhile
+	es.closeMu.Lock()
+	defer es.closeMu.Unlock()
+
+	if es.closed {
+		reader.Close()
+		end.finish(es.ctx.Err(), true)
 		return false
 	}
 
-	// Set headers
-	es.client.setStreamHeaders(req)
+	// The interrupted connection failed; the new one is reported when the stream ends
+	es.end.Swap(end).finish(cause, false)
 
-	// Make the request
-	resp, err := es.client.httpClient.Do(req)
-	if err != nil {
-		return false
-	}
+	es.reader = es.track(reader)
+	es.scanner = sse.NewScanner(es.reader)
+	es.touch()
 
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return false
+	// A restarted answer replaces the output so far
+	if strategy == ReconnectRestart && es.partial != nil {
+		es.partial = &partialOutput{}
 	}
-
-	// Update stream with new connection
-	es.response = resp
-	es.scanner = sse.NewScanner(resp.Body)
+	es.reconnected = &StreamReconnect{Attempt: attempt, Strategy: strategy, Err: cause}
 
 	return true
 }
@@ -289,35 +477,198 @@ func parseSSEData(data string, v interface{}) error {
 	return nil
 }
 
-// Stream represents a generic streaming response wrapper.
-type Stream[T any] struct {
-	stream *eventStream
+// streamErrorChunk is the part of a stream chunk that reports a mid-stream error.
+type streamErrorChunk struct {
+	Provider string `json:"provider"`
+	Error    *struct {
+		Code     json.RawMessage        `json:"code"`
+		Message  string                 `json:"message"`
+		Type     string                 `json:"type"`
+		Metadata map[string]interface{} `json:"metadata"`
+	} `json:"error"`
+	Choices []struct {
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
 }
 
-// Events returns a channel that receives streaming events.
-func (s *Stream[T]) Events() <-chan T {
-	events := make(chan T)
+// parseStreamErrorEvent returns the error reported by a stream chunk, if any. When a
+// provider fails after streaming has begun, OpenRouter sends a chunk with an error
+// object and a finish reason of "error". The error is a *StreamError wrapping a
+// *RequestError with the error's code, message and metadata.
+func parseStreamErrorEvent(data []byte) error {
+	if !bytes.Contains(data, []byte(`"error"`)) {
+		return nil
+	}
 
-	go func() {
-		defer close(events)
+	var chunk streamErrorChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil
+	}
 
-		for event := range s.stream.Events() {
-			// Parse the event data into the response type
-			var response T
-			if err := parseSSEData(event.Data, &response); err != nil {
-				s.stream.setError(err)
-				return
+	if chunk.Error == nil {
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason == "error" {
+				return &StreamError{Message: "stream finished with an error"}
 			}
+		}
+		return nil
+	}
+
+	reqErr := &RequestError{
+		Message:  chunk.Error.Message,
+		Type:     chunk.Error.Type,
+		Metadata: chunk.Error.Metadata,
+	}
+
+	// The code is either an HTTP status code or a string such as "server_error"
+	var code interface{}
+	if json.Unmarshal(chunk.Error.Code, &code) == nil {
+		switch c := code.(type) {
+		case float64:
+			reqErr.StatusCode = int(c)
+			reqErr.Code = strconv.Itoa(int(c))
+		case string:
+			reqErr.Code = c
+			reqErr.StatusCode, _ = strconv.Atoi(c)
+		}
+	}
+
+	if reqErr.ProviderName() == "" && chunk.Provider != "" {
+		reqErr.Metadata = maps.Clone(reqErr.Metadata)
+		if reqErr.Metadata == nil {
+			reqErr.Metadata = make(map[string]interface{})
+		}
+		reqErr.Metadata["provider_name"] = chunk.Provider
+	}
 
-			select {
-			case events <- response:
-			case <-s.stream.ctx.Done():
+	return &StreamError{Err: reqErr, Message: "provider error mid-stream"}
+}
+
+// Stream represents a generic streaming response wrapper. Read it with one of All,
+// Recv or Events; they consume the same events, so using several splits the events
+// between them.
+type Stream[T any] struct {
+	stream *eventStream
+
+	eventsOnce sync.Once
+	events     chan T
+}
+
+// All returns an iterator over the stream's responses. An error ends the iteration
+// and is yielded with a zero response. The stream is closed when the loop ends,
+// including when it breaks early.
+//
+// Example:
+//
+//	for chunk, err := range stream.All() {
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    fmt.Print(chunk.Choices[0].Delta.Content)
+//	}
+func (s *Stream[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer s.Close()
+
+		for {
+			response, err := s.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(response, nil) {
 				return
 			}
 		}
-	}()
+	}
+}
+
+// Recv returns the next response of the stream. It returns io.EOF when the stream
+// ends normally, and the stream's error when it fails. Recv reads the stream in the
+// caller's goroutine and is not safe for concurrent use.
+//
+// Example:
+//
+//	defer stream.Close()
+//	for {
+//	    chunk, err := stream.Recv()
+//	    if errors.Is(err, io.EOF) {
+//	        break
+//	    }
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Print(chunk.Choices[0].Delta.Content)
+//	}
+func (s *Stream[T]) Recv() (T, error) {
+	var response T
+
+	event, ok := <-s.stream.Events()
+	if !ok {
+		s.stream.finish()
+		if err := s.stream.Err(); err != nil {
+			return response, err
+		}
+		return response, io.EOF
+	}
+
+	// Parse the event data into the response type
+	if err := parseSSEData(event.Data, &response); err != nil {
+		s.stream.setError(err)
+		s.Close()
+		var zero T
+		return zero, err
+	}
+
+	if event.Reconnect != nil {
+		if receiver, ok := any(&response).(interface{ setReconnect(*StreamReconnect) }); ok {
+			receiver.setReconnect(event.Reconnect)
+		}
+	}
+
+	if reporter, ok := any(response).(usageReporter); ok {
+		usage := reporter.tokenUsage()
+		s.stream.client.recordUsage(usage)
+		s.stream.setUsage(usage)
+	}
+
+	if s.stream.observer != nil {
+		s.stream.observer.StreamChunk(response, event.received)
+	}
+
+	return response, nil
+}
+
+// Events returns a channel that receives streaming events. The channel is closed
+// when the stream ends; check Err afterwards. Repeated calls return the same channel.
+func (s *Stream[T]) Events() <-chan T {
+	s.eventsOnce.Do(func() {
+		s.events = make(chan T)
 
-	return events
+		go func() {
+			defer close(s.events)
+			defer s.stream.finish()
+
+			for {
+				response, err := s.Recv()
+				if err != nil {
+					return
+				}
+
+				select {
+				case s.events <- response:
+				case <-s.stream.ctx.Done():
+					return
+				}
+			}
+		}()
+	})
+
+	return s.events
 }
 
 // Err returns any error that occurred during streaming.
@@ -325,6 +676,19 @@ func (s *Stream[T]) Err() error {
 	return s.stream.Err()
 }
 
+// Usage returns the token usage reported by the stream, or nil if it has not been
+// received yet. OpenRouter sends usage in the final chunk, so it is available once
+// the events channel is drained. Enable WithUsageAccounting to include the cost.
+func (s *Stream[T]) Usage() *Usage {
+	return s.stream.Usage()
+}
+
+// Hedge reports the hedged requests that opened the stream when WithHedging is
+// used, or nil otherwise.
+func (s *Stream[T]) Hedge() *HedgeReport {
+	return s.stream.hedge
+}
+
 // Close closes the stream.
 func (s *Stream[T]) Close() error {
 	return s.stream.Close()
@@ -0,0 +1,193 @@
+package openrouter
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// defaultReconnectAttempts is the number of reconnection attempts per interruption
+// when MaxAttempts is not set.
+const defaultReconnectAttempts = 3
+
+// ReconnectStrategy determines what a stream requests when it reconnects.
+type ReconnectStrategy string
+
+const (
+	// ReconnectRestart sends the original request again. The model starts its
+	// answer over, so consumers must discard the output received so far.
+	ReconnectRestart ReconnectStrategy = "restart"
+	// ReconnectContinue sends the request with the text received so far appended as
+	// an assistant prefill (for completions, appended to the prompt), so the model
+	// resumes its answer. Streams that received tool calls restart instead.
+	ReconnectContinue ReconnectStrategy = "continue"
+)
+
+// StreamReconnectConfig enables reconnecting a stream after a network error.
+// Either way the interrupted generation is billed, and so is the new request.
+type StreamReconnectConfig struct {
+	// Strategy is what the stream requests when it reconnects. Defaults to ReconnectRestart.
+	Strategy ReconnectStrategy
+	// MaxAttempts is the number of reconnection attempts after each interruption. Defaults to 3.
+	MaxAttempts int
+}
+
+// StreamReconnect is attached to the first chunk received after a stream reconnected.
+type StreamReconnect struct {
+	// Attempt is the reconnection attempt that succeeded, starting at 1
+	Attempt int
+	// Strategy is the strategy used. When it is ReconnectRestart, the chunk starts a
+	// new answer and the output received before it must be discarded.
+	Strategy ReconnectStrategy
+	// Err is the error that interrupted the stream
+	Err error
+}
+
+// WithStreamReconnect reconnects ChatCompleteStream after a network error instead of
+// ending it with an error. The first chunk after a reconnection has its Reconnect
+// field set; ChatStreamAccumulator discards its state when the stream restarted.
+// Other requests ignore this option.
+//
+// Example:
+//
+//	stream, err := client.ChatCompleteStream(ctx, messages,
+//	    openrouter.WithStreamReconnect(openrouter.StreamReconnectConfig{
+//	        Strategy: openrouter.ReconnectContinue,
+//	    }),
+//	)
+//	...
+//	for chunk := range stream.Events() {
+//	    if chunk.Reconnect != nil && chunk.Reconnect.Strategy == openrouter.ReconnectRestart {
+//	        output.Reset()
+//	    }
+//	    ...
+//	}
+func WithStreamReconnect(config StreamReconnectConfig) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		setStreamReconnect(r, config)
+	}
+}
+
+// WithCompletionStreamReconnect reconnects CompleteStream after a network error. See
+// WithStreamReconnect.
+func WithCompletionStreamReconnect(config StreamReconnectConfig) CompletionOption {
+	return func(r *CompletionRequest) {
+		setStreamReconnect(r, config)
+	}
+}
+
+// setStreamReconnect is a generic helper to set the stream reconnection configuration.
+func setStreamReconnect[T RequestConfig](r T, config StreamReconnectConfig) {
+	switch req := any(r).(type) {
+	case *ChatCompletionRequest:
+		req.streamReconnect = &config
+	case *CompletionRequest:
+		req.streamReconnect = &config
+	}
+}
+
+// requestStreamReconnect returns the reconnection configuration of a chat or
+// completion request, if set.
+func requestStreamReconnect(body interface{}) *StreamReconnectConfig {
+	switch r := body.(type) {
+	case *ChatCompletionRequest:
+		return r.streamReconnect
+	case *CompletionRequest:
+		return r.streamReconnect
+	}
+	return nil
+}
+
+// maxAttempts returns the number of reconnection attempts after each interruption.
+func (c *StreamReconnectConfig) maxAttempts() int {
+	if c.MaxAttempts < 1 {
+		return defaultReconnectAttempts
+	}
+	return c.MaxAttempts
+}
+
+// setReconnect attaches reconnection details to a chunk.
+func (r *ChatCompletionResponse) setReconnect(reconnect *StreamReconnect) {
+	r.Reconnect = reconnect
+}
+
+// setReconnect attaches reconnection details to a chunk.
+func (r *CompletionResponse) setReconnect(reconnect *StreamReconnect) {
+	r.Reconnect = reconnect
+}
+
+// partialOutput collects the text of the first choice of a stream, for ReconnectContinue.
+type partialOutput struct {
+	text      strings.Builder
+	toolCalls bool
+}
+
+// partialChunk is the part of a chat or completion chunk that partialOutput needs.
+type partialChunk struct {
+	Choices []struct {
+		Index int    `json:"index"`
+		Text  string `json:"text"`
+		Delta *struct {
+			Content   interface{}       `json:"content"`
+			ToolCalls []json.RawMessage `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// add records the output of a chunk.
+func (p *partialOutput) add(data string) {
+	var chunk partialChunk
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return
+	}
+
+	for _, choice := range chunk.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+		p.text.WriteString(choice.Text)
+		if choice.Delta != nil {
+			if content, ok := choice.Delta.Content.(string); ok {
+				p.text.WriteString(content)
+			}
+			if len(choice.Delta.ToolCalls) > 0 {
+				p.toolCalls = true
+			}
+		}
+	}
+}
+
+// continuable reports whether the output can be continued with a prefill.
+func (p *partialOutput) continuable() bool {
+	return !p.toolCalls && p.text.Len() > 0
+}
+
+// continueRequest returns a copy of a chat or completion request that resumes after
+// the output so far.
+func (p *partialOutput) continueRequest(body interface{}) interface{} {
+	partial := p.text.String()
+
+	switch r := body.(type) {
+	case *ChatCompletionRequest:
+		continued := *r
+		continued.Messages = slices.Clone(r.Messages)
+
+		// Extend an existing prefill rather than adding a second assistant message
+		if n := len(continued.Messages); n > 0 && continued.Messages[n-1].Role == "assistant" {
+			if prefill, ok := continued.Messages[n-1].Content.(string); ok && len(continued.Messages[n-1].ToolCalls) == 0 {
+				continued.Messages[n-1].Content = prefill + partial
+				return &continued
+			}
+		}
+
+		continued.Messages = append(continued.Messages, CreateAssistantMessage(partial))
+		return &continued
+
+	case *CompletionRequest:
+		continued := *r
+		continued.Prompt += partial
+		return &continued
+	}
+
+	return body
+}
@@ -0,0 +1,204 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// interruptedStreamServer answers each request with the next entry of responses.
+// Responses flagged as interrupted end with a dropped connection instead of [DONE].
+// The decoded requests are appended to requests.
+func interruptedStreamServer(t *testing.T, requests *[]ChatCompletionRequest, responses ...interruptedResponse) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		mu.Lock()
+		*requests = append(*requests, req)
+		resp := responses[min(len(*requests), len(responses))-1]
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if resp.interrupted {
+			// Promise more than is sent, so the connection is dropped mid-stream
+			w.Header().Set("Content-Length", "100000")
+		}
+
+		for _, content := range resp.contents {
+			fmt.Fprintf(w, "data: {\"id\":\"r\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+		}
+		if !resp.interrupted {
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}
+	}))
+}
+
+// interruptedResponse is a stream response of interruptedStreamServer.
+type interruptedResponse struct {
+	contents    []string
+	interrupted bool
+}
+
+func TestStreamDoesNotReconnectByDefault(t *testing.T) {
+	var requests []ChatCompletionRequest
+	server := interruptedStreamServer(t, &requests,
+		interruptedResponse{contents: []string{"Hel"}, interrupted: true},
+		interruptedResponse{contents: []string{"Hello"}},
+	)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var chunks []ChatCompletionResponse
+	for chunk := range stream.Events() {
+		chunks = append(chunks, chunk)
+	}
+
+	if _, ok := IsStreamError(stream.Err()); !ok {
+		t.Fatalf("expected a stream error, got %v", stream.Err())
+	}
+	if len(requests) != 1 {
+		t.Errorf("expected no reconnection, got %d requests", len(requests))
+	}
+	if got := ConcatenateChatStreamResponses(chunks); got != "Hel" {
+		t.Errorf("unexpected content: %q", got)
+	}
+}
+
+func TestStreamReconnectRestart(t *testing.T) {
+	var requests []ChatCompletionRequest
+	server := interruptedStreamServer(t, &requests,
+		interruptedResponse{contents: []string{"Hel"}, interrupted: true},
+		interruptedResponse{contents: []string{"Hel", "lo"}},
+	)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithStreamReconnect(StreamReconnectConfig{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	acc := NewChatStreamAccumulator()
+	var reconnects []*StreamReconnect
+	for chunk := range stream.Events() {
+		if chunk.Reconnect != nil {
+			reconnects = append(reconnects, chunk.Reconnect)
+		}
+		acc.Add(chunk)
+	}
+
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reconnects) != 1 || reconnects[0].Strategy != ReconnectRestart || reconnects[0].Attempt != 1 || reconnects[0].Err == nil {
+		t.Fatalf("expected one restart marker, got %+v", reconnects)
+	}
+	if len(requests) != 2 || len(requests[1].Messages) != 1 {
+		t.Fatalf("expected the original request to be sent again, got %+v", requests)
+	}
+	if got := acc.Response().Choices[0].Message.Content; got != "Hello" {
+		t.Errorf("expected the accumulator to discard the partial answer, got %q", got)
+	}
+}
+
+func TestStreamReconnectContinue(t *testing.T) {
+	var requests []ChatCompletionRequest
+	server := interruptedStreamServer(t, &requests,
+		interruptedResponse{contents: []string{"Hel"}, interrupted: true},
+		interruptedResponse{contents: []string{"lo"}},
+	)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithStreamReconnect(StreamReconnectConfig{Strategy: ReconnectContinue, MaxAttempts: 1}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	acc := NewChatStreamAccumulator()
+	var strategy ReconnectStrategy
+	for chunk := range stream.Events() {
+		if chunk.Reconnect != nil {
+			strategy = chunk.Reconnect.Strategy
+		}
+		acc.Add(chunk)
+	}
+
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strategy != ReconnectContinue {
+		t.Errorf("expected a continue marker, got %q", strategy)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	prefill := requests[1].Messages
+	if len(prefill) != 2 || prefill[1].Role != "assistant" || prefill[1].Content != "Hel" {
+		t.Errorf("expected the partial answer as a prefill, got %+v", prefill)
+	}
+	if got := acc.Response().Choices[0].Message.Content; got != "Hello" {
+		t.Errorf("expected the answer to resume, got %q", got)
+	}
+}
+
+func TestPartialOutputContinueRequest(t *testing.T) {
+	partial := &partialOutput{}
+	partial.add(`{"choices":[{"index":0,"delta":{"content":"Once "}}]}`)
+	partial.add(`{"choices":[{"index":1,"delta":{"content":"ignored"}}]}`)
+	partial.add(`{"choices":[{"index":0,"delta":{"content":"upon"}}]}`)
+
+	if !partial.continuable() {
+		t.Fatal("expected text output to be continuable")
+	}
+
+	// An existing prefill is extended
+	chat := &ChatCompletionRequest{Messages: []Message{
+		CreateUserMessage("Tell a story"),
+		CreateAssistantMessage("Story: "),
+	}}
+	continued := partial.continueRequest(chat).(*ChatCompletionRequest)
+	if len(continued.Messages) != 2 || continued.Messages[1].Content != "Story: Once upon" {
+		t.Errorf("unexpected messages: %+v", continued.Messages)
+	}
+	if chat.Messages[1].Content != "Story: " {
+		t.Errorf("expected the original request to be unchanged")
+	}
+
+	completion := &CompletionRequest{Prompt: "Tell a story: "}
+	if got := partial.continueRequest(completion).(*CompletionRequest).Prompt; got != "Tell a story: Once upon" {
+		t.Errorf("unexpected prompt: %q", got)
+	}
+
+	// Tool calls cannot be prefilled
+	partial.add(`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1"}]}}]}`)
+	if partial.continuable() {
+		t.Error("expected output with tool calls not to be continuable")
+	}
+}
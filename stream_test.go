@@ -0,0 +1,201 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chunkStreamServer streams count chat chunks numbered from 0, followed by tail.
+// disconnected is closed when the client goes away before the stream ends.
+func chunkStreamServer(t *testing.T, count int, tail string, disconnected chan struct{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+
+		for i := 0; i < count; i++ {
+			fmt.Fprintf(w, "data: {\"id\":\"s\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"%d\"}}]}\n\n", i)
+			flusher.Flush()
+
+			select {
+			case <-r.Context().Done():
+				if disconnected != nil {
+					close(disconnected)
+				}
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+
+		fmt.Fprint(w, tail)
+	}))
+}
+
+// openTestChatStream opens a chat stream against server.
+func openTestChatStream(t *testing.T, server *httptest.Server) *ChatStream {
+	t.Helper()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return stream
+}
+
+func TestStreamAll(t *testing.T) {
+	server := chunkStreamServer(t, 3, "data: [DONE]\n\n", nil)
+	defer server.Close()
+
+	stream := openTestChatStream(t, server)
+
+	var content strings.Builder
+	for chunk, err := range stream.All() {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content.(string))
+	}
+
+	if content.String() != "012" {
+		t.Errorf("unexpected content: %q", content.String())
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("unexpected stream error: %v", err)
+	}
+}
+
+func TestStreamAllBreakClosesStream(t *testing.T) {
+	disconnected := make(chan struct{})
+	server := chunkStreamServer(t, 1000, "data: [DONE]\n\n", disconnected)
+	defer server.Close()
+
+	stream := openTestChatStream(t, server)
+
+	received := 0
+	for _, err := range stream.All() {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		received++
+		if received == 2 {
+			break
+		}
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected breaking the loop to close the connection")
+	}
+
+	select {
+	case <-stream.stream.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the reading goroutine to stop")
+	}
+}
+
+func TestStreamAllYieldsError(t *testing.T) {
+	tail := `data: {"error":{"code":502,"message":"Provider disconnected"},"choices":[{"index":0,"delta":{},"finish_reason":"error"}]}` + "\n\n"
+	server := chunkStreamServer(t, 2, tail, nil)
+	defer server.Close()
+
+	stream := openTestChatStream(t, server)
+
+	var chunks int
+	var iterErr error
+	for _, err := range stream.All() {
+		if err != nil {
+			iterErr = err
+			continue
+		}
+		chunks++
+	}
+
+	// The error chunk itself is yielded before the error
+	if chunks != 3 {
+		t.Errorf("expected 3 chunks, got %d", chunks)
+	}
+	if reqErr, ok := IsRequestError(iterErr); !ok || reqErr.StatusCode != 502 {
+		t.Errorf("expected the mid-stream error, got %v", iterErr)
+	}
+}
+
+func TestStreamRecv(t *testing.T) {
+	server := chunkStreamServer(t, 2, "data: [DONE]\n\n", nil)
+	defer server.Close()
+
+	stream := openTestChatStream(t, server)
+	defer stream.Close()
+
+	var content strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content.(string))
+	}
+
+	if content.String() != "01" {
+		t.Errorf("unexpected content: %q", content.String())
+	}
+
+	// The end of the stream is reported again
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF after the end, got %v", err)
+	}
+}
+
+func TestStreamRecvInvalidChunk(t *testing.T) {
+	server := chunkStreamServer(t, 1, "data: {not json}\n\ndata: [DONE]\n\n", nil)
+	defer server.Close()
+
+	stream := openTestChatStream(t, server)
+	defer stream.Close()
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := stream.Recv()
+	if _, ok := IsStreamError(err); !ok {
+		t.Fatalf("expected a stream error for the invalid chunk, got %v", err)
+	}
+	if _, err := stream.Recv(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("expected the error to persist, got %v", err)
+	}
+}
+
+func TestStreamEventsReturnsSameChannel(t *testing.T) {
+	server := chunkStreamServer(t, 5, "data: [DONE]\n\n", nil)
+	defer server.Close()
+
+	stream := openTestChatStream(t, server)
+	defer stream.Close()
+
+	if stream.Events() != stream.Events() {
+		t.Fatal("expected repeated calls to return the same channel")
+	}
+
+	received := 0
+	for range stream.Events() {
+		received++
+	}
+	if received != 5 {
+		t.Errorf("expected 5 chunks, got %d", received)
+	}
+}
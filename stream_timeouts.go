@@ -0,0 +1,158 @@
+package openrouter
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// StreamTimeouts limits how long a stream may take. Zero values disable a limit.
+//
+// When any limit is set, the stream is not subject to the HTTP client's Timeout
+// (set with WithTimeout), which would otherwise cut off long but healthy streams.
+type StreamTimeouts struct {
+	// FirstToken limits the time from sending the request to the first event. Keep-alive
+	// comments such as ": OPENROUTER PROCESSING" do not count as the first event.
+	FirstToken time.Duration
+	// Idle limits the time between data received once the stream is open. Keep-alive
+	// comments count as data, so a stream that OpenRouter reports as still processing
+	// is not idle. Time spent waiting for the caller to read events does not count.
+	Idle time.Duration
+	// Total caps the duration of the whole stream.
+	Total time.Duration
+}
+
+// requestStreamTimeouts returns the stream timeouts of a chat or completion request, if set.
+func requestStreamTimeouts(body interface{}) *StreamTimeouts {
+	switch r := body.(type) {
+	case *ChatCompletionRequest:
+		return r.streamTimeouts
+	case *CompletionRequest:
+		return r.streamTimeouts
+	}
+	return nil
+}
+
+// watch enforces the stream timeouts until the stream ends. A timeout fails the
+// stream with a *StreamError wrapping ErrStreamFirstTokenTimeout, ErrStreamIdleTimeout
+// or ErrStreamTotalTimeout.
+func (es *eventStream) watch(timeouts StreamTimeouts) {
+	firstToken := newStreamTimer(timeouts.FirstToken)
+	total := newStreamTimer(timeouts.Total)
+	// The idle timer starts once the stream is open
+	idle := &streamTimer{}
+	defer firstToken.stop()
+	defer idle.stop()
+	defer total.stop()
+
+	firstEvent := es.firstEvent
+
+	for {
+		select {
+		case <-es.ctx.Done():
+			return
+		case <-es.done:
+			return
+
+		case <-es.activity:
+			idle.reset(timeouts.Idle)
+		case <-firstEvent:
+			firstToken.stop()
+			firstEvent = nil
+
+		case <-firstToken.c:
+			es.abort(&StreamError{
+				Err:     ErrStreamFirstTokenTimeout,
+				Message: fmt.Sprintf("no event within %v", timeouts.FirstToken),
+			})
+			return
+		case <-idle.c:
+			if es.delivering.Load() {
+				idle.reset(timeouts.Idle)
+				continue
+			}
+			es.abort(&StreamError{
+				Err:     ErrStreamIdleTimeout,
+				Message: fmt.Sprintf("no data for %v", timeouts.Idle),
+			})
+			return
+		case <-total.c:
+			es.abort(&StreamError{
+				Err:     ErrStreamTotalTimeout,
+				Message: fmt.Sprintf("stream exceeded %v", timeouts.Total),
+			})
+			return
+		}
+	}
+}
+
+// abort fails the stream with err and stops reading.
+func (es *eventStream) abort(err error) {
+	es.setError(err)
+	es.cancel()
+}
+
+// touch signals that data was received.
+func (es *eventStream) touch() {
+	select {
+	case es.activity <- struct{}{}:
+	default:
+	}
+}
+
+// track wraps a stream body so that reads signal activity.
+func (es *eventStream) track(reader io.ReadCloser) io.ReadCloser {
+	return &activityReader{ReadCloser: reader, stream: es}
+}
+
+// activityReader signals activity on its stream whenever data is read.
+type activityReader struct {
+	io.ReadCloser
+	stream *eventStream
+}
+
+// Read implements io.Reader.
+func (r *activityReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.stream.touch()
+	}
+	return n, err
+}
+
+// streamTimer is a timer whose channel is nil while it is disabled or stopped.
+type streamTimer struct {
+	timer *time.Timer
+	c     <-chan time.Time
+}
+
+// newStreamTimer starts a timer for d, or returns a disabled timer if d is not positive.
+func newStreamTimer(d time.Duration) *streamTimer {
+	t := &streamTimer{}
+	if d > 0 {
+		t.timer = time.NewTimer(d)
+		t.c = t.timer.C
+	}
+	return t
+}
+
+// reset restarts the timer for d, unless the timer is disabled.
+func (t *streamTimer) reset(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	if t.timer == nil {
+		t.timer = time.NewTimer(d)
+	} else {
+		t.timer.Reset(d)
+	}
+	t.c = t.timer.C
+}
+
+// stop stops the timer.
+func (t *streamTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.c = nil
+}
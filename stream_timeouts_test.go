@@ -0,0 +1,200 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// pacedStreamServer writes the given SSE lines, waiting interval before each, then
+// holds the connection open until the client goes away.
+func pacedStreamServer(t *testing.T, interval time.Duration, lines ...string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+		flusher.Flush()
+
+		for _, line := range lines {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(interval):
+			}
+			fmt.Fprint(w, line)
+			flusher.Flush()
+		}
+
+		<-r.Context().Done()
+	}))
+}
+
+// drainChatStream reads a chat stream to the end and returns its error.
+func drainChatStream(t *testing.T, client *Client, opts ...ChatCompletionOption) (int, error) {
+	t.Helper()
+
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")},
+		append([]ChatCompletionOption{WithModel("test-model")}, opts...)...)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+
+	chunks := 0
+	for range stream.Events() {
+		chunks++
+	}
+	return chunks, stream.Err()
+}
+
+const timeoutTestChunk = `data: {"id":"s","choices":[{"index":0,"delta":{"content":"x"}}]}` + "\n\n"
+
+func TestStreamFirstTokenTimeout(t *testing.T) {
+	// Keep-alive comments keep the stream from idling but are not a first token
+	keepAlive := ": OPENROUTER PROCESSING\n\n"
+	server := pacedStreamServer(t, 20*time.Millisecond, keepAlive, keepAlive, keepAlive, keepAlive, keepAlive, keepAlive, keepAlive, keepAlive)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	_, err := drainChatStream(t, client, WithStreamTimeouts(StreamTimeouts{
+		FirstToken: 120 * time.Millisecond,
+		Idle:       60 * time.Millisecond,
+	}))
+
+	if !errors.Is(err, ErrStreamFirstTokenTimeout) {
+		t.Fatalf("expected a first token timeout, got %v", err)
+	}
+	if _, ok := IsStreamError(err); !ok {
+		t.Errorf("expected a StreamError, got %T", err)
+	}
+}
+
+func TestStreamFirstTokenTimeoutWhileOpening(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Reading the body lets the server notice the client going away
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	start := time.Now()
+	_, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithStreamTimeouts(StreamTimeouts{FirstToken: 50 * time.Millisecond}),
+	)
+	if !errors.Is(err, ErrStreamFirstTokenTimeout) {
+		t.Fatalf("expected a first token timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the stream to fail fast, took %v", elapsed)
+	}
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	server := pacedStreamServer(t, 10*time.Millisecond, timeoutTestChunk, timeoutTestChunk)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	chunks, err := drainChatStream(t, client, WithStreamTimeouts(StreamTimeouts{
+		FirstToken: time.Second,
+		Idle:       100 * time.Millisecond,
+	}))
+
+	if !errors.Is(err, ErrStreamIdleTimeout) {
+		t.Fatalf("expected an idle timeout, got %v", err)
+	}
+	if chunks != 2 {
+		t.Errorf("expected the chunks before the stall, got %d", chunks)
+	}
+}
+
+func TestStreamIdleTimeoutIgnoresSlowReader(t *testing.T) {
+	server := pacedStreamServer(t, time.Millisecond, timeoutTestChunk, "data: [DONE]\n\n")
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := client.ChatCompleteStream(context.Background(), []Message{CreateUserMessage("hi")},
+		WithModel("test-model"),
+		WithStreamTimeouts(StreamTimeouts{Idle: 50 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	events := stream.Events()
+	<-events
+	time.Sleep(200 * time.Millisecond)
+	for range events {
+	}
+
+	if err := stream.Err(); err != nil {
+		t.Errorf("expected a slow reader not to time out, got %v", err)
+	}
+}
+
+func TestStreamTotalTimeout(t *testing.T) {
+	lines := make([]string, 50)
+	for i := range lines {
+		lines[i] = timeoutTestChunk
+	}
+	server := pacedStreamServer(t, 20*time.Millisecond, lines...)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	chunks, err := drainChatStream(t, client, WithStreamTimeouts(StreamTimeouts{
+		Idle:  100 * time.Millisecond,
+		Total: 150 * time.Millisecond,
+	}))
+
+	if !errors.Is(err, ErrStreamTotalTimeout) {
+		t.Fatalf("expected a total timeout, got %v", err)
+	}
+	if chunks == 0 || chunks >= len(lines) {
+		t.Errorf("expected the stream to be cut off, got %d chunks", chunks)
+	}
+}
+
+func TestStreamTimeoutsReplaceClientTimeout(t *testing.T) {
+	lines := []string{
+		`data: {"id":"c","choices":[{"index":0,"text":"a"}]}` + "\n\n",
+		`data: {"id":"c","choices":[{"index":0,"text":"b"}]}` + "\n\n",
+		`data: {"id":"c","choices":[{"index":0,"text":"c"}]}` + "\n\n",
+		`data: {"id":"c","choices":[{"index":0,"text":"d"}]}` + "\n\n",
+		"data: [DONE]\n\n",
+	}
+	server := pacedStreamServer(t, 50*time.Millisecond, lines...)
+	defer server.Close()
+
+	// The stream outlives the HTTP client's timeout
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithTimeout(100*time.Millisecond))
+	stream, err := client.CompleteStream(context.Background(), "hi",
+		WithCompletionModel("test-model"),
+		WithCompletionStreamTimeouts(StreamTimeouts{Idle: time.Second}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var responses []CompletionResponse
+	for resp := range stream.Events() {
+		responses = append(responses, resp)
+	}
+
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ConcatenateCompletionStreamResponses(responses); got != "abcd" {
+		t.Errorf("unexpected text: %q", got)
+	}
+}
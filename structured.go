@@ -0,0 +1,127 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WithValidationRetries sets how many times ChatCompleteInto re-prompts the model
+// when its response does not match the expected schema. The validation error is
+// sent back to the model so it can correct its output. Defaults to 0.
+func WithValidationRetries(retries int) ChatCompletionOption {
+	return func(r *ChatCompletionRequest) {
+		r.validationRetries = retries
+	}
+}
+
+// ChatCompleteInto sends a chat completion request with a JSON schema response
+// format generated from T, then decodes and validates the result. Strict mode is
+// enabled unless T contains maps, interfaces or json.RawMessage.
+//
+// The response is validated against the generated schema before being decoded
+// into T. When validation fails and WithValidationRetries is set, the invalid
+// answer and the validation error are appended to the conversation and the model
+// is asked again. If no valid response is produced, the returned error is a
+// *StructuredOutputError and the last response is returned alongside it.
+//
+// Example:
+//
+//	type Weather struct {
+//	    Location    string  `json:"location"`
+//	    Temperature float64 `json:"temperature"`
+//	}
+//
+//	weather, resp, err := openrouter.ChatCompleteInto[Weather](ctx, client, messages,
+//	    openrouter.WithModel("openai/gpt-4o"),
+//	    openrouter.WithValidationRetries(2),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%s: %.1f°C (%d tokens)\n", weather.Location, weather.Temperature, resp.Usage.TotalTokens)
+func ChatCompleteInto[T any](ctx context.Context, client *Client, messages []Message, opts ...ChatCompletionOption) (T, *ChatCompletionResponse, error) {
+	var result T
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	schema, strict, err := generateJSONSchema(t)
+	if err != nil {
+		return result, nil, err
+	}
+
+	requestOpts := append(append([]ChatCompletionOption(nil), opts...), WithJSONSchema(schemaName(t), strict, schema))
+
+	settings := &ChatCompletionRequest{}
+	for _, opt := range requestOpts {
+		opt(settings)
+	}
+
+	conversation := append([]Message(nil), messages...)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := client.ChatComplete(ctx, conversation, requestOpts...)
+		if err != nil {
+			return result, nil, err
+		}
+
+		content, err := decodeStructuredOutput(resp, schema, &result)
+		if err == nil {
+			return result, resp, nil
+		}
+
+		var outputErr *StructuredOutputError
+		if !errors.As(err, &outputErr) || attempt >= settings.validationRetries {
+			return result, resp, err
+		}
+
+		conversation = append(conversation,
+			CreateAssistantMessage(content),
+			CreateUserMessage(fmt.Sprintf(
+				"Your previous response did not match the required JSON schema: %v. "+
+					"Respond again with only valid JSON that matches the schema.", outputErr.Err)),
+		)
+	}
+}
+
+// decodeStructuredOutput validates the first choice of a response against the schema
+// and decodes it into v. It returns the raw content that was inspected.
+func decodeStructuredOutput(resp *ChatCompletionResponse, schema map[string]interface{}, v interface{}) (string, error) {
+	if len(resp.Choices) == 0 {
+		return "", &StructuredOutputError{Err: errors.New("response contained no choices")}
+	}
+
+	content, ok := resp.Choices[0].Message.Content.(string)
+	if !ok {
+		return "", &StructuredOutputError{Err: errors.New("response content is not text")}
+	}
+
+	data := []byte(trimJSONCodeFence(content))
+
+	if err := ValidateJSON(data, schema); err != nil {
+		return content, &StructuredOutputError{Content: content, Err: err}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return content, &StructuredOutputError{Content: content, Err: err}
+	}
+
+	return content, nil
+}
+
+// trimJSONCodeFence removes a surrounding markdown code fence that some models
+// add around JSON output even when a response format is requested.
+func trimJSONCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+
+	trimmed = strings.TrimPrefix(trimmed, "```json")
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	trimmed = strings.TrimSuffix(trimmed, "```")
+
+	return strings.TrimSpace(trimmed)
+}
@@ -0,0 +1,180 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type structuredWeather struct {
+	Location    string  `json:"location"`
+	Temperature float64 `json:"temperature"`
+	Unit        string  `json:"unit" enum:"celsius,fahrenheit"`
+}
+
+func structuredServer(t *testing.T, contents []string, requests *[]ChatCompletionRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		*requests = append(*requests, req)
+
+		content := contents[len(*requests)-1]
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(finalResponse(content))
+	}))
+}
+
+func TestChatCompleteInto(t *testing.T) {
+	var requests []ChatCompletionRequest
+	server := structuredServer(t, []string{
+		"```json\n{\"location\":\"Paris\",\"temperature\":21.5,\"unit\":\"celsius\"}\n```",
+	}, &requests)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	weather, resp, err := ChatCompleteInto[structuredWeather](context.Background(), client,
+		[]Message{CreateUserMessage("Weather in Paris?")},
+		WithModel("test-model"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if weather.Location != "Paris" || weather.Temperature != 21.5 || weather.Unit != "celsius" {
+		t.Errorf("unexpected result: %+v", weather)
+	}
+	if resp == nil || resp.ID != "chat-final" {
+		t.Errorf("expected response to be returned, got %+v", resp)
+	}
+
+	format := requests[0].ResponseFormat
+	if format == nil || format.Type != "json_schema" || format.JSONSchema == nil {
+		t.Fatalf("expected json_schema response format, got %+v", format)
+	}
+	if format.JSONSchema.Name != "structured_weather" || !format.JSONSchema.Strict {
+		t.Errorf("unexpected schema settings: %+v", format.JSONSchema)
+	}
+}
+
+func TestChatCompleteIntoValidationRetry(t *testing.T) {
+	var requests []ChatCompletionRequest
+	server := structuredServer(t, []string{
+		`{"location":"Paris","temperature":21.5,"unit":"kelvin"}`,
+		`{"location":"Paris","temperature":294.65,"unit":"celsius"}`,
+	}, &requests)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	weather, _, err := ChatCompleteInto[structuredWeather](context.Background(), client,
+		[]Message{CreateUserMessage("Weather in Paris?")},
+		WithModel("test-model"),
+		WithValidationRetries(1),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if weather.Temperature != 294.65 {
+		t.Errorf("expected result from second attempt, got %+v", weather)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	retryMessages := requests[1].Messages
+	if len(retryMessages) != 3 {
+		t.Fatalf("expected 3 messages in retry request, got %d", len(retryMessages))
+	}
+	if retryMessages[1].Role != "assistant" {
+		t.Errorf("expected invalid answer to be replayed, got role %q", retryMessages[1].Role)
+	}
+	feedback, _ := retryMessages[2].Content.(string)
+	if !strings.Contains(feedback, "unit") {
+		t.Errorf("expected feedback to mention the invalid field, got %q", feedback)
+	}
+}
+
+func TestChatCompleteIntoInvalidOutput(t *testing.T) {
+	var requests []ChatCompletionRequest
+	server := structuredServer(t, []string{`{"location":"Paris"}`}, &requests)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	_, resp, err := ChatCompleteInto[structuredWeather](context.Background(), client,
+		[]Message{CreateUserMessage("Weather in Paris?")},
+		WithModel("test-model"),
+	)
+
+	outputErr, ok := IsStructuredOutputError(err)
+	if !ok {
+		t.Fatalf("expected StructuredOutputError, got %v", err)
+	}
+	if outputErr.Content != `{"location":"Paris"}` {
+		t.Errorf("unexpected content: %q", outputErr.Content)
+	}
+	if valErr, ok := IsValidationError(err); !ok || valErr.Field != "temperature" {
+		t.Errorf("expected validation error for temperature, got %v", err)
+	}
+	if resp == nil {
+		t.Error("expected last response to be returned")
+	}
+	if len(requests) != 1 {
+		t.Errorf("expected no retries, got %d requests", len(requests))
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	schema, err := JSONSchemaFor[struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+		Note  *string  `json:"note"`
+	}]()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		data          string
+		expectedField string
+		valid         bool
+	}{
+		{"valid", `{"name":"a","count":1,"tags":["x"],"note":null}`, "", true},
+		{"invalid JSON", `{"name":`, "", false},
+		{"missing required", `{"name":"a","count":1,"tags":[]}`, "note", false},
+		{"wrong type", `{"name":1,"count":1,"tags":[],"note":null}`, "name", false},
+		{"non-integer", `{"name":"a","count":1.5,"tags":[],"note":null}`, "count", false},
+		{"wrong item type", `{"name":"a","count":1,"tags":["x",2],"note":null}`, "tags[1]", false},
+		{"additional property", `{"name":"a","count":1,"tags":[],"note":"n","extra":true}`, "extra", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSON([]byte(tt.data), schema)
+			if tt.valid {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			valErr, ok := IsValidationError(err)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if valErr.Field != tt.expectedField {
+				t.Errorf("expected field %q, got %q", tt.expectedField, valErr.Field)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const (
+	// defaultMaxToolIterations is the default number of model round trips a ToolRunner performs.
+	defaultMaxToolIterations = 10
+)
+
+// ToolHandler executes a single tool call. It receives the raw JSON arguments
+// produced by the model and returns the content of the tool message sent back.
+type ToolHandler func(ctx context.Context, arguments string) (string, error)
+
+// ToolRunnerHooks contains optional callbacks invoked at each step of a tool run.
+// OnToolCall and OnToolResult may be called concurrently when tool calls run in parallel.
+type ToolRunnerHooks struct {
+	// OnResponse is called after each chat completion response is received.
+	OnResponse func(iteration int, resp *ChatCompletionResponse)
+	// OnToolCall is called before a tool handler is executed.
+	OnToolCall func(call ToolCall)
+	// OnToolResult is called after a tool handler returns.
+	OnToolResult func(call ToolCall, result string, err error)
+}
+
+// ToolRunner drives the request → tool calls → tool results loop automatically.
+// Handlers are registered per function name and executed whenever the model
+// requests them, until the model produces a final assistant message or the
+// iteration limit is reached.
+//
+// A ToolRunner is safe for concurrent use once configured.
+type ToolRunner struct {
+	client        *Client
+	tools         []Tool
+	handlers      map[string]ToolHandler
+	maxIterations int
+	hooks         ToolRunnerHooks
+}
+
+// ToolRunnerOption is a functional option for configuring a ToolRunner.
+type ToolRunnerOption func(*ToolRunner)
+
+// WithToolHandler registers a tool definition together with the handler that executes it.
+func WithToolHandler(tool Tool, handler ToolHandler) ToolRunnerOption {
+	return func(r *ToolRunner) {
+		r.Register(tool, handler)
+	}
+}
+
+// WithMaxIterations sets the maximum number of chat completion requests a run may make.
+// Values below 1 keep the default of 10.
+func WithMaxIterations(maxIterations int) ToolRunnerOption {
+	return func(r *ToolRunner) {
+		if maxIterations < 1 {
+			maxIterations = defaultMaxToolIterations
+		}
+		r.maxIterations = maxIterations
+	}
+}
+
+// WithToolRunnerHooks sets callbacks that observe each step of a run.
+func WithToolRunnerHooks(hooks ToolRunnerHooks) ToolRunnerOption {
+	return func(r *ToolRunner) {
+		r.hooks = hooks
+	}
+}
+
+// NewToolRunner creates a new ToolRunner that sends requests through the given client.
+//
+// Example:
+//
+//	runner := openrouter.NewToolRunner(client,
+//	    openrouter.WithToolHandler(weatherTool, func(ctx context.Context, args string) (string, error) {
+//	        var params struct {
+//	            Location string `json:"location"`
+//	        }
+//	        if err := json.Unmarshal([]byte(args), &params); err != nil {
+//	            return "", err
+//	        }
+//	        return getWeather(params.Location)
+//	    }),
+//	    openrouter.WithMaxIterations(5),
+//	)
+//
+//	result, err := runner.Run(ctx, messages, openrouter.WithModel("openai/gpt-4o-mini"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(result.FinalResponse.Choices[0].Message.Content)
+func NewToolRunner(client *Client, opts ...ToolRunnerOption) *ToolRunner {
+	r := &ToolRunner{
+		client:        client,
+		handlers:      make(map[string]ToolHandler),
+		maxIterations: defaultMaxToolIterations,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Register adds a tool definition and its handler to the runner.
+// Registering a tool with an existing name replaces the previous handler.
+func (r *ToolRunner) Register(tool Tool, handler ToolHandler) {
+	if tool.Type == "" {
+		tool.Type = "function"
+	}
+
+	if _, exists := r.handlers[tool.Function.Name]; exists {
+		for i := range r.tools {
+			if r.tools[i].Function.Name == tool.Function.Name {
+				r.tools[i] = tool
+			}
+		}
+	} else {
+		r.tools = append(r.tools, tool)
+	}
+
+	r.handlers[tool.Function.Name] = handler
+}
+
+// Tools returns the tool definitions registered with the runner.
+func (r *ToolRunner) Tools() []Tool {
+	return append([]Tool(nil), r.tools...)
+}
+
+// ToolRunResult contains the outcome of a tool run.
+type ToolRunResult struct {
+	// Messages is the full transcript, including the input messages, every
+	// assistant message and every tool result.
+	Messages []Message
+	// Responses contains every chat completion response received, in order.
+	Responses []*ChatCompletionResponse
+	// FinalResponse is the last response received from the model.
+	FinalResponse *ChatCompletionResponse
+	// Iterations is the number of chat completion requests made.
+	Iterations int
+}
+
+// Run sends the messages to the model and executes requested tool calls until the
+// model returns a message without tool calls. The registered tools are sent with
+// every request; options are applied after them and may override them.
+//
+// Tool calls are executed concurrently when the request enables parallel tool
+// calls via WithParallelToolCalls. Handler errors and unknown tool names are
+// reported back to the model as tool messages rather than aborting the run.
+//
+// If the iteration limit is reached, the partial result is returned together
+// with ErrMaxIterationsReached.
+func (r *ToolRunner) Run(ctx context.Context, messages []Message, opts ...ChatCompletionOption) (*ToolRunResult, error) {
+	requestOpts := append([]ChatCompletionOption{WithTools(r.tools...)}, opts...)
+	parallel := r.parallelToolCalls(requestOpts)
+
+	result := &ToolRunResult{
+		Messages: append([]Message(nil), messages...),
+	}
+
+	for result.Iterations < r.maxIterations {
+		resp, err := r.client.ChatComplete(ctx, result.Messages, requestOpts...)
+		if err != nil {
+			return result, err
+		}
+
+		result.Iterations++
+		result.Responses = append(result.Responses, resp)
+		result.FinalResponse = resp
+
+		if r.hooks.OnResponse != nil {
+			r.hooks.OnResponse(result.Iterations, resp)
+		}
+
+		if len(resp.Choices) == 0 {
+			return result, nil
+		}
+
+		assistant := resp.Choices[0].Message
+		result.Messages = append(result.Messages, assistant)
+
+		if len(assistant.ToolCalls) == 0 {
+			return result, nil
+		}
+
+		result.Messages = append(result.Messages, r.executeToolCalls(ctx, assistant.ToolCalls, parallel)...)
+
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	return result, ErrMaxIterationsReached
+}
+
+// parallelToolCalls reports whether the request options enable parallel tool calls.
+func (r *ToolRunner) parallelToolCalls(opts []ChatCompletionOption) bool {
+	req := &ChatCompletionRequest{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req.ParallelToolCalls != nil && *req.ParallelToolCalls
+}
+
+// executeToolCalls runs the handlers for the given tool calls and returns the
+// resulting tool messages in the same order as the calls.
+func (r *ToolRunner) executeToolCalls(ctx context.Context, calls []ToolCall, parallel bool) []Message {
+	results := make([]Message, len(calls))
+
+	if !parallel || len(calls) == 1 {
+		for i, call := range calls {
+			results[i] = r.executeToolCall(ctx, call)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call ToolCall) {
+			defer wg.Done()
+			results[i] = r.executeToolCall(ctx, call)
+		}(i, call)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// executeToolCall runs the handler for a single tool call and builds its tool message.
+func (r *ToolRunner) executeToolCall(ctx context.Context, call ToolCall) Message {
+	if r.hooks.OnToolCall != nil {
+		r.hooks.OnToolCall(call)
+	}
+
+	var result string
+	var err error
+
+	handler, ok := r.handlers[call.Function.Name]
+	if !ok {
+		err = fmt.Errorf("unknown tool %q", call.Function.Name)
+	} else {
+		result, err = handler(ctx, call.Function.Arguments)
+	}
+
+	if r.hooks.OnToolResult != nil {
+		r.hooks.OnToolResult(call, result, err)
+	}
+
+	if err != nil {
+		result = fmt.Sprintf("Error: %v", err)
+	}
+
+	return CreateToolMessage(result, call.ID)
+}
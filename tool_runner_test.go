@@ -0,0 +1,322 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testTool(name string) Tool {
+	return Tool{
+		Type: "function",
+		Function: Function{
+			Name: name,
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}
+}
+
+func toolCallResponse(calls ...ToolCall) ChatCompletionResponse {
+	return ChatCompletionResponse{
+		ID: "chat-tools",
+		Choices: []Choice{{
+			Message:      Message{Role: "assistant", ToolCalls: calls},
+			FinishReason: "tool_calls",
+		}},
+	}
+}
+
+func finalResponse(content string) ChatCompletionResponse {
+	return ChatCompletionResponse{
+		ID: "chat-final",
+		Choices: []Choice{{
+			Message:      Message{Role: "assistant", Content: content},
+			FinishReason: "stop",
+		}},
+	}
+}
+
+func TestToolRunnerRun(t *testing.T) {
+	var requests []ChatCompletionRequest
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		mu.Lock()
+		requests = append(requests, req)
+		count := len(requests)
+		mu.Unlock()
+
+		var resp ChatCompletionResponse
+		if count == 1 {
+			resp = toolCallResponse(ToolCall{
+				ID:       "call_1",
+				Type:     "function",
+				Function: FunctionCall{Name: "get_weather", Arguments: `{"location":"Paris"}`},
+			})
+		} else {
+			resp = finalResponse("It is sunny in Paris.")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	var gotArgs string
+	var responses int
+	runner := NewToolRunner(client,
+		WithToolHandler(testTool("get_weather"), func(ctx context.Context, arguments string) (string, error) {
+			gotArgs = arguments
+			return `{"conditions":"sunny"}`, nil
+		}),
+		WithToolRunnerHooks(ToolRunnerHooks{
+			OnResponse: func(iteration int, resp *ChatCompletionResponse) {
+				responses++
+			},
+		}),
+	)
+
+	result, err := runner.Run(context.Background(),
+		[]Message{CreateUserMessage("What's the weather in Paris?")},
+		WithModel("test-model"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotArgs != `{"location":"Paris"}` {
+		t.Errorf("unexpected handler arguments: %q", gotArgs)
+	}
+	if result.Iterations != 2 {
+		t.Errorf("expected 2 iterations, got %d", result.Iterations)
+	}
+	if responses != 2 {
+		t.Errorf("expected OnResponse to be called 2 times, got %d", responses)
+	}
+	if len(result.Messages) != 4 {
+		t.Fatalf("expected 4 transcript messages, got %d", len(result.Messages))
+	}
+	if result.Messages[2].Role != "tool" || result.Messages[2].ToolCallID != "call_1" {
+		t.Errorf("unexpected tool message: %+v", result.Messages[2])
+	}
+	if result.FinalResponse.Choices[0].Message.Content != "It is sunny in Paris." {
+		t.Errorf("unexpected final content: %v", result.FinalResponse.Choices[0].Message.Content)
+	}
+
+	if len(requests[0].Tools) != 1 || requests[0].Tools[0].Function.Name != "get_weather" {
+		t.Errorf("expected registered tool to be sent, got %+v", requests[0].Tools)
+	}
+	if len(requests[1].Messages) != 3 {
+		t.Errorf("expected 3 messages in second request, got %d", len(requests[1].Messages))
+	}
+}
+
+func TestToolRunnerHandlerErrors(t *testing.T) {
+	var secondRequest ChatCompletionRequest
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var resp ChatCompletionResponse
+		if calls == 1 {
+			resp = toolCallResponse(
+				ToolCall{ID: "call_1", Type: "function", Function: FunctionCall{Name: "failing", Arguments: "{}"}},
+				ToolCall{ID: "call_2", Type: "function", Function: FunctionCall{Name: "missing", Arguments: "{}"}},
+			)
+		} else {
+			json.NewDecoder(r.Body).Decode(&secondRequest)
+			resp = finalResponse("done")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	var resultErrs []error
+	runner := NewToolRunner(client,
+		WithToolHandler(testTool("failing"), func(ctx context.Context, arguments string) (string, error) {
+			return "", errors.New("boom")
+		}),
+		WithToolRunnerHooks(ToolRunnerHooks{
+			OnToolResult: func(call ToolCall, result string, err error) {
+				resultErrs = append(resultErrs, err)
+			},
+		}),
+	)
+
+	_, err := runner.Run(context.Background(), []Message{CreateUserMessage("Hi")}, WithModel("test-model"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resultErrs) != 2 || resultErrs[0] == nil || resultErrs[1] == nil {
+		t.Fatalf("expected two handler errors, got %v", resultErrs)
+	}
+
+	toolMessages := secondRequest.Messages[2:]
+	if len(toolMessages) != 2 {
+		t.Fatalf("expected 2 tool messages, got %d", len(toolMessages))
+	}
+	if toolMessages[0].Content != "Error: boom" {
+		t.Errorf("unexpected tool message content: %v", toolMessages[0].Content)
+	}
+	if toolMessages[1].Content != `Error: unknown tool "missing"` {
+		t.Errorf("unexpected tool message content: %v", toolMessages[1].Content)
+	}
+}
+
+func TestToolRunnerMaxIterations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(toolCallResponse(ToolCall{
+			ID: "call_loop", Type: "function", Function: FunctionCall{Name: "loop", Arguments: "{}"},
+		}))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	runner := NewToolRunner(client,
+		WithToolHandler(testTool("loop"), func(ctx context.Context, arguments string) (string, error) {
+			return "again", nil
+		}),
+		WithMaxIterations(3),
+	)
+
+	result, err := runner.Run(context.Background(), []Message{CreateUserMessage("Hi")}, WithModel("test-model"))
+	if !errors.Is(err, ErrMaxIterationsReached) {
+		t.Fatalf("expected ErrMaxIterationsReached, got %v", err)
+	}
+	if result.Iterations != 3 {
+		t.Errorf("expected 3 iterations, got %d", result.Iterations)
+	}
+
+	// Values below 1 keep the default instead of failing without a request
+	for _, maxIterations := range []int{0, -1} {
+		runner := NewToolRunner(client, WithMaxIterations(maxIterations))
+		if runner.maxIterations != defaultMaxToolIterations {
+			t.Errorf("WithMaxIterations(%d): expected the default, got %d", maxIterations, runner.maxIterations)
+		}
+	}
+}
+
+func TestToolRunnerParallel(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var resp ChatCompletionResponse
+		if calls == 1 {
+			resp = toolCallResponse(
+				ToolCall{ID: "call_1", Type: "function", Function: FunctionCall{Name: "slow", Arguments: "{}"}},
+				ToolCall{ID: "call_2", Type: "function", Function: FunctionCall{Name: "slow", Arguments: "{}"}},
+			)
+		} else {
+			resp = finalResponse("done")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	var running, maxRunning int32
+	runner := NewToolRunner(client,
+		WithToolHandler(testTool("slow"), func(ctx context.Context, arguments string) (string, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				current := atomic.LoadInt32(&maxRunning)
+				if n <= current || atomic.CompareAndSwapInt32(&maxRunning, current, n) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return "ok", nil
+		}),
+	)
+
+	parallel := true
+	result, err := runner.Run(context.Background(), []Message{CreateUserMessage("Hi")},
+		WithModel("test-model"),
+		WithParallelToolCalls(&parallel),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if atomic.LoadInt32(&maxRunning) != 2 {
+		t.Errorf("expected tool calls to run concurrently, max concurrency was %d", maxRunning)
+	}
+	if result.Messages[2].ToolCallID != "call_1" || result.Messages[3].ToolCallID != "call_2" {
+		t.Error("expected tool messages to preserve call order")
+	}
+}
+
+func TestToolRunnerPreservesReasoning(t *testing.T) {
+	var requests []ChatCompletionRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			w.Write([]byte(`{"id":"chat-r","choices":[{"message":{"role":"assistant","content":"",` +
+				`"reasoning":"I should check the weather.",` +
+				`"reasoning_details":[{"type":"reasoning.text","text":"I should check the weather.","signature":"sig-1"}],` +
+				`"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{}"}}]},` +
+				`"finish_reason":"tool_calls"}]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(finalResponse("Sunny."))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	runner := NewToolRunner(client,
+		WithToolHandler(testTool("get_weather"), func(ctx context.Context, arguments string) (string, error) {
+			return "sunny", nil
+		}),
+	)
+
+	if _, err := runner.Run(context.Background(),
+		[]Message{CreateUserMessage("Weather?")},
+		WithModel("anthropic/claude-sonnet-4"),
+		WithReasoningMaxTokens(1024),
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	assistant := requests[1].Messages[1]
+	if assistant.Reasoning != "I should check the weather." {
+		t.Errorf("expected reasoning to be sent back, got %q", assistant.Reasoning)
+	}
+	if len(assistant.ReasoningDetails) != 1 || assistant.ReasoningDetails[0].Signature != "sig-1" {
+		t.Errorf("expected reasoning details to be sent back unmodified, got %+v", assistant.ReasoningDetails)
+	}
+}